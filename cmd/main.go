@@ -2,12 +2,16 @@ package main
 
 import (
 	"context"
+	"fmt"
 	"log"
 	"net/http"
+	"os"
+	"strconv"
 	"time"
 
 	"github.com/Vladroon22/CVmaker/internal/database"
 	"github.com/Vladroon22/CVmaker/internal/handlers"
+	"github.com/Vladroon22/CVmaker/internal/pdf"
 	"github.com/Vladroon22/CVmaker/internal/repository"
 	"github.com/Vladroon22/CVmaker/internal/service"
 	tlsserver "github.com/Vladroon22/CVmaker/internal/tls-server"
@@ -20,14 +24,22 @@ func main() {
 		log.Fatalln(err)
 	}
 
-	db := database.NewDB()
-	if err := db.Connect(context.Background()); err != nil {
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		db := connectDB()
+		if err := migrate(db, os.Args[2:]); err != nil {
+			log.Fatalln(err)
+		}
+		db.Close()
+		return
+	}
+
+	if err := pdf.LoadFonts(); err != nil {
 		log.Fatalln(err)
 	}
-	redis := database.NewRedis()
 
-	repo := repository.NewRepo(db, redis)
-	srv := service.NewService(repo)
+	repo, closeRepo := newRepository(os.Getenv("Storage"))
+	policy := service.ExpiryPolicyFromEnv()
+	srv := service.NewService(repo, policy)
 	h := handlers.NewHandler(srv)
 
 	router := mux.NewRouter()
@@ -41,11 +53,32 @@ func main() {
 	sub := router.PathPrefix("/user/").Subrouter()
 	sub.Use(h.AuthMiddleWare)
 
-	sub.HandleFunc("/deleteCV", h.DeleteCV).Methods("GET")
+	sub.HandleFunc("/deleteCV", h.DeleteCV).Methods("POST")
+	sub.HandleFunc("/trash", h.Trash).Methods("GET")
+	sub.HandleFunc("/restoreCV", h.RestoreCV).Methods("POST")
+	sub.HandleFunc("/purgeCV", h.PurgeCV).Methods("POST")
 	sub.HandleFunc("/makeCV", h.MakeCV).Methods("POST")
+	sub.HandleFunc("/cloneCV", h.CloneCV).Methods("POST")
+	sub.HandleFunc("/editCV", h.EditCVPage).Methods("GET")
+	sub.HandleFunc("/editCV", h.EditCV).Methods("POST")
+	sub.HandleFunc("/revisions", h.Revisions).Methods("GET")
+	sub.HandleFunc("/revision", h.ViewRevision).Methods("GET")
+	sub.HandleFunc("/diffCV", h.DiffRevisions).Methods("GET")
+	sub.HandleFunc("/restoreRevision", h.RestoreRevision).Methods("POST")
+	sub.HandleFunc("/renewCV", h.RenewCV).Methods("POST")
+	sub.HandleFunc("/archive", h.Archive).Methods("GET")
 	sub.HandleFunc("/profile", h.UserCV).Methods("GET")
 	sub.HandleFunc("/listCV", h.ListCV).Methods("GET")
 	sub.HandleFunc("/downloadCV", h.DownloadPDF).Methods("GET")
+	sub.HandleFunc("/photo", h.Photo).Methods("GET")
+	sub.HandleFunc("/export", h.Export).Methods("GET")
+	sub.HandleFunc("/delete-account", h.DeleteAccountPage).Methods("GET")
+	sub.HandleFunc("/delete-account", h.DeleteAccount).Methods("POST")
+
+	sweepCtx, stopSweep := context.WithCancel(context.Background())
+	defer stopSweep()
+
+	go service.NewSweeper(repo, policy).Run(sweepCtx)
 
 	serv := tlsserver.New()
 
@@ -61,7 +94,8 @@ func main() {
 		ctx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
 		defer cancel()
 
-		db.Close()
+		stopSweep()
+		closeRepo()
 
 		if err := serv.Shutdown(ctx); err != nil {
 			log.Printf("HTTP server shutdown error: %v", err)
@@ -70,3 +104,76 @@ func main() {
 
 	log.Println("Gracefull shutdown")
 }
+
+// newRepository creates storage chosen by name: postgres (default), redis or memory.
+// Postgres uses redis as cache of CVs when Redis env is set
+func newRepository(storage string) (service.Repository, func()) {
+	switch storage {
+	case "", "postgres":
+		db := connectDB()
+		if err := db.MigrateUp(context.Background()); err != nil {
+			log.Fatalln(err)
+		}
+
+		var cache *database.Redis
+		if os.Getenv("Redis") != "" {
+			cache = database.NewRedis()
+		}
+		return repository.NewPostgres(db, cache), db.Close
+	case "redis":
+		return repository.NewRedis(database.NewRedis()), func() {}
+	case "memory":
+		log.Println("Memory storage: data will be lost on restart")
+		return repository.NewMemory(), func() {}
+	default:
+		log.Fatalf("unknown storage: %s", storage)
+		return nil, nil
+	}
+}
+
+func connectDB() *database.DataBase {
+	db := database.NewDB()
+	if err := db.Connect(context.Background()); err != nil {
+		log.Fatalln(err)
+	}
+	return db
+}
+
+// migrate handles "migrate up|down [steps]|status" subcommand
+func migrate(db *database.DataBase, args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("usage: %s migrate up|down [steps]|status", os.Args[0])
+	}
+
+	ctx := context.Background()
+
+	switch args[0] {
+	case "up":
+		return db.MigrateUp(ctx)
+	case "down":
+		steps := 1
+		if len(args) > 1 {
+			n, err := strconv.Atoi(args[1])
+			if err != nil || n < 1 {
+				return fmt.Errorf("wrong number of steps: %s", args[1])
+			}
+			steps = n
+		}
+		return db.MigrateDown(ctx, steps)
+	case "status":
+		status, err := db.MigrationStatus(ctx)
+		if err != nil {
+			return err
+		}
+		for _, st := range status {
+			applied := "pending"
+			if st.AppliedAt != nil {
+				applied = "applied at " + st.AppliedAt.Format(time.DateTime)
+			}
+			fmt.Printf("%04d_%s\t%s\n", st.Version, st.Name, applied)
+		}
+		return nil
+	default:
+		return fmt.Errorf("unknown migrate command: %s", args[0])
+	}
+}
@@ -37,6 +37,27 @@ func (c *Cache) Set(id string, cv *ent.CV) {
 	c.cache[id] = append(c.cache[id], cv)
 }
 
+// SetAll replaces all cached CVs of user
+func (c *Cache) SetAll(id string, cvs []ent.CV) {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+
+	if c.cache == nil {
+		c.cache = make(map[string][]*ent.CV)
+	}
+
+	exp := time.Now().Add(20 * time.Minute)
+
+	cached := make([]*ent.CV, 0, len(cvs))
+	for i := range cvs {
+		cv := cvs[i]
+		cv.Exp = exp
+		cached = append(cached, &cv)
+	}
+
+	c.cache[id] = cached
+}
+
 func (c *Cache) GetLen(id string) int {
 	c.mtx.RLock()
 	defer c.mtx.RUnlock()
@@ -44,11 +65,11 @@ func (c *Cache) GetLen(id string) int {
 	return len(c.cache[id])
 }
 
-func (c *Cache) Get(prof, id string) (*ent.CV, bool) {
+func (c *Cache) Get(cvID, id string) (*ent.CV, bool) {
 	c.mtx.RLock()
 	defer c.mtx.RUnlock()
 
-	return binSearch(c.cache[id], id, prof)
+	return binSearch(c.cache[id], cvID)
 }
 
 func (c *Cache) FromToSliceByID(id string) []ent.CV {
@@ -66,13 +87,41 @@ func (c *Cache) FromToSliceByID(id string) []ent.CV {
 	return cvs
 }
 
-func (c *Cache) Delete(prof, id string) {
+func (c *Cache) Delete(cvID, id string) {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+
+	cvs := c.cache[id]
+	for i, cv := range cvs {
+		if cv.ID == cvID {
+			c.cache[id] = append(cvs[:i], cvs[i+1:]...)
+			break
+		}
+	}
+
+	if len(c.cache[id]) == 0 {
+		delete(c.cache, id)
+	}
+}
+
+// DeleteUser drops all cached CVs of user
+func (c *Cache) DeleteUser(id string) {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+
+	delete(c.cache, id)
+}
+
+// Replace swaps user's cached CV with the same ID for cv under one lock,
+// CVs which aren't cached are left to be fetched from storage
+func (c *Cache) Replace(id string, cv *ent.CV) {
 	c.mtx.Lock()
 	defer c.mtx.Unlock()
 
-	for _, cv := range c.cache[id] {
-		if cv.Profession == prof {
-			delete(c.cache, id)
+	for i, old := range c.cache[id] {
+		if old.ID == cv.ID {
+			cv.Exp = time.Now().Add(20 * time.Minute)
+			c.cache[id][i] = cv
 			return
 		}
 	}
@@ -110,7 +159,7 @@ func (c *Cache) cleanExpired() {
 	}
 }
 
-func binSearch(cvs []*ent.CV, goal string, prof string) (*ent.CV, bool) {
+func binSearch(cvs []*ent.CV, goal string) (*ent.CV, bool) {
 	if len(cvs) == 0 {
 		return nil, false
 	}
@@ -122,7 +171,7 @@ func binSearch(cvs []*ent.CV, goal string, prof string) (*ent.CV, bool) {
 
 	for beg <= end {
 		mid := beg + (end-beg)/2
-		if cvs[mid].ID == goal && cvs[mid].Profession == prof {
+		if cvs[mid].ID == goal {
 			return cvs[mid], true
 		} else if cvs[mid].ID < goal {
 			beg = mid + 1
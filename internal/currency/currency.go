@@ -0,0 +1,96 @@
+package currency
+
+import (
+	_ "embed"
+	"encoding/csv"
+	"log"
+	"strconv"
+	"strings"
+)
+
+//go:embed iso4217.csv
+var table string
+
+// Currency is an active ISO 4217 currency, Style tells how amounts are written in its home locale:
+// "en" is $1,234, "ru" is 1 234 ₽, "de" is 1.234 €
+type Currency struct {
+	Code   string
+	Name   string
+	Symbol string
+	Style  string
+}
+
+var currencies, byCode = load()
+
+func load() ([]Currency, map[string]Currency) {
+	r := csv.NewReader(strings.NewReader(table))
+	r.Comma = ';'
+
+	records, err := r.ReadAll()
+	if err != nil {
+		log.Fatalln("ISO 4217 table is broken: ", err)
+	}
+
+	list := make([]Currency, 0, len(records))
+	codes := make(map[string]Currency, len(records))
+	for _, rec := range records[1:] {
+		c := Currency{Code: rec[0], Name: rec[1], Symbol: rec[2], Style: rec[3]}
+		list = append(list, c)
+		codes[c.Code] = c
+	}
+	return list, codes
+}
+
+// List gives all currencies ordered by code
+func List() []Currency {
+	return currencies
+}
+
+// Lookup finds currency by its code, case of the code doesn't matter
+func Lookup(code string) (Currency, bool) {
+	c, ok := byCode[strings.ToUpper(strings.TrimSpace(code))]
+	return c, ok
+}
+
+// Format writes amount the way it's written in home locale of the currency,
+// unknown codes are kept after the amount
+func Format(amount int, code string) string {
+	c, ok := Lookup(code)
+	if !ok {
+		return strings.TrimSpace(group(amount, ",") + " " + code)
+	}
+
+	sign := c.Symbol
+	if sign == "" {
+		sign = c.Code
+	}
+
+	switch c.Style {
+	case "ru":
+		return group(amount, " ") + " " + sign
+	case "de":
+		return group(amount, ".") + " " + sign
+	default:
+		if c.Symbol == "" {
+			return group(amount, ",") + " " + c.Code
+		}
+		return c.Symbol + group(amount, ",")
+	}
+}
+
+// group separates thousands of n with sep
+func group(n int, sep string) string {
+	digits := strconv.Itoa(n)
+	sign := ""
+	if n < 0 {
+		sign, digits = "-", digits[1:]
+	}
+
+	parts := []string{}
+	for len(digits) > 3 {
+		parts = append([]string{digits[len(digits)-3:]}, parts...)
+		digits = digits[:len(digits)-3]
+	}
+	parts = append([]string{digits}, parts...)
+	return sign + strings.Join(parts, sep)
+}
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS cv_skills;
+DROP TABLE IF EXISTS cvs;
@@ -0,0 +1,26 @@
+CREATE TABLE IF NOT EXISTS cvs (
+	id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+	user_id UUID REFERENCES users(id) NOT NULL,
+	profession VARCHAR(100) NOT NULL,
+	name VARCHAR(50) NOT NULL,
+	surname VARCHAR(50) NOT NULL,
+	age INT NOT NULL,
+	email VARCHAR(100) NOT NULL,
+	city VARCHAR(100) NOT NULL,
+	salary INT NOT NULL,
+	currency VARCHAR(10) NOT NULL,
+	phone VARCHAR(30) NOT NULL,
+	education VARCHAR(100) NOT NULL,
+	description TEXT NOT NULL,
+	created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+	updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+	UNIQUE (user_id, profession)
+);
+
+CREATE TABLE IF NOT EXISTS cv_skills (
+	id SERIAL PRIMARY KEY,
+	cv_id UUID REFERENCES cvs(id) ON DELETE CASCADE NOT NULL,
+	kind VARCHAR(4) NOT NULL,
+	position INT NOT NULL,
+	skill TEXT NOT NULL
+);
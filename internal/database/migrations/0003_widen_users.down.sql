@@ -0,0 +1,2 @@
+ALTER TABLE users ALTER COLUMN email TYPE VARCHAR(30);
+ALTER TABLE users ALTER COLUMN name TYPE VARCHAR(20);
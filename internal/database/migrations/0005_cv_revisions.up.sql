@@ -0,0 +1,8 @@
+CREATE TABLE IF NOT EXISTS cv_revisions (
+	id SERIAL PRIMARY KEY,
+	cv_id UUID REFERENCES cvs(id) ON DELETE CASCADE NOT NULL,
+	version INT NOT NULL,
+	data JSONB NOT NULL,
+	created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+	UNIQUE (cv_id, version)
+);
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS cvs_user_id_idx;
+ALTER TABLE cvs ADD CONSTRAINT cvs_user_id_profession_key UNIQUE (user_id, profession);
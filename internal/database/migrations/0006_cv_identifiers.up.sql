@@ -0,0 +1,2 @@
+ALTER TABLE cvs DROP CONSTRAINT IF EXISTS cvs_user_id_profession_key;
+CREATE INDEX IF NOT EXISTS cvs_user_id_idx ON cvs (user_id);
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS cvs_status_expires_at_idx;
+ALTER TABLE cvs DROP COLUMN IF EXISTS status;
+ALTER TABLE cvs DROP COLUMN IF EXISTS expires_at;
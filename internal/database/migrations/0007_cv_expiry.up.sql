@@ -0,0 +1,3 @@
+ALTER TABLE cvs ADD COLUMN IF NOT EXISTS expires_at TIMESTAMP NOT NULL DEFAULT (now() AT TIME ZONE 'utc' + INTERVAL '7 days');
+ALTER TABLE cvs ADD COLUMN IF NOT EXISTS status VARCHAR(10) NOT NULL DEFAULT 'active';
+CREATE INDEX IF NOT EXISTS cvs_status_expires_at_idx ON cvs (status, expires_at);
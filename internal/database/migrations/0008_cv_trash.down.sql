@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS cvs_deleted_at_idx;
+DELETE FROM cvs WHERE deleted_at IS NOT NULL;
+ALTER TABLE cvs DROP COLUMN IF EXISTS deleted_at;
@@ -0,0 +1,2 @@
+ALTER TABLE cvs ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMP;
+CREATE INDEX IF NOT EXISTS cvs_deleted_at_idx ON cvs (deleted_at) WHERE deleted_at IS NOT NULL;
@@ -0,0 +1,6 @@
+DROP TABLE IF EXISTS account_tombstones;
+
+ALTER TABLE cvs DROP CONSTRAINT IF EXISTS cvs_user_id_fkey;
+ALTER TABLE cvs ADD CONSTRAINT cvs_user_id_fkey FOREIGN KEY (user_id) REFERENCES users(id);
+ALTER TABLE sessions DROP CONSTRAINT IF EXISTS sessions_user_id_fkey;
+ALTER TABLE sessions ADD CONSTRAINT sessions_user_id_fkey FOREIGN KEY (user_id) REFERENCES users(id);
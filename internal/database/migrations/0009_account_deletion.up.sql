@@ -0,0 +1,11 @@
+ALTER TABLE sessions DROP CONSTRAINT IF EXISTS sessions_user_id_fkey;
+ALTER TABLE sessions ADD CONSTRAINT sessions_user_id_fkey FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE;
+ALTER TABLE cvs DROP CONSTRAINT IF EXISTS cvs_user_id_fkey;
+ALTER TABLE cvs ADD CONSTRAINT cvs_user_id_fkey FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE;
+
+CREATE TABLE IF NOT EXISTS account_tombstones (
+	user_id UUID PRIMARY KEY,
+	email_sha256 CHAR(64) NOT NULL,
+	deleted_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+CREATE INDEX IF NOT EXISTS account_tombstones_email_idx ON account_tombstones (email_sha256);
@@ -0,0 +1 @@
+ALTER TABLE cvs ADD COLUMN IF NOT EXISTS experience JSONB NOT NULL DEFAULT '[]';
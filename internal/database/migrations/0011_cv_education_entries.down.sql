@@ -0,0 +1,2 @@
+ALTER TABLE cvs ALTER COLUMN education DROP DEFAULT;
+ALTER TABLE cvs ALTER COLUMN education TYPE VARCHAR(100) USING LEFT(COALESCE(education->0->>'degree', ''), 100);
@@ -0,0 +1,9 @@
+ALTER TABLE cvs ALTER COLUMN education DROP DEFAULT;
+ALTER TABLE cvs ALTER COLUMN education TYPE JSONB USING (
+	CASE WHEN education = '' THEN '[]'::jsonb
+	ELSE jsonb_build_array(jsonb_build_object(
+		'institution', '', 'degree', education, 'field', '', 'start_year', 0, 'end_year', 0
+	))
+	END
+);
+ALTER TABLE cvs ALTER COLUMN education SET DEFAULT '[]';
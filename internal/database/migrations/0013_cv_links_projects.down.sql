@@ -0,0 +1,2 @@
+ALTER TABLE cvs DROP COLUMN IF EXISTS projects;
+ALTER TABLE cvs DROP COLUMN IF EXISTS links;
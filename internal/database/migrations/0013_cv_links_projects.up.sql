@@ -0,0 +1,2 @@
+ALTER TABLE cvs ADD COLUMN IF NOT EXISTS links JSONB NOT NULL DEFAULT '[]';
+ALTER TABLE cvs ADD COLUMN IF NOT EXISTS projects JSONB NOT NULL DEFAULT '[]';
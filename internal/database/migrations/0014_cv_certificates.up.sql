@@ -0,0 +1 @@
+ALTER TABLE cvs ADD COLUMN IF NOT EXISTS certificates JSONB NOT NULL DEFAULT '[]';
@@ -0,0 +1 @@
+ALTER TABLE cvs DROP COLUMN IF EXISTS photo;
@@ -0,0 +1 @@
+ALTER TABLE cvs ADD COLUMN IF NOT EXISTS photo BYTEA;
@@ -0,0 +1,2 @@
+ALTER TABLE cvs DROP COLUMN IF EXISTS hide_age;
+ALTER TABLE cvs DROP COLUMN IF EXISTS birth_date;
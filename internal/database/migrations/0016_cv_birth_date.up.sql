@@ -0,0 +1,2 @@
+ALTER TABLE cvs ADD COLUMN IF NOT EXISTS birth_date DATE;
+ALTER TABLE cvs ADD COLUMN IF NOT EXISTS hide_age BOOLEAN NOT NULL DEFAULT false;
@@ -0,0 +1,4 @@
+ALTER TABLE cvs DROP COLUMN IF EXISTS negotiable;
+ALTER TABLE cvs DROP COLUMN IF EXISTS salary_net;
+ALTER TABLE cvs DROP COLUMN IF EXISTS salary_period;
+ALTER TABLE cvs DROP COLUMN IF EXISTS salary_max;
@@ -0,0 +1,6 @@
+ALTER TABLE cvs ADD COLUMN IF NOT EXISTS salary_max INT NOT NULL DEFAULT 0;
+ALTER TABLE cvs ADD COLUMN IF NOT EXISTS salary_period VARCHAR(10) NOT NULL DEFAULT 'monthly';
+ALTER TABLE cvs ADD COLUMN IF NOT EXISTS salary_net BOOLEAN NOT NULL DEFAULT false;
+ALTER TABLE cvs ADD COLUMN IF NOT EXISTS negotiable BOOLEAN NOT NULL DEFAULT false;
+
+UPDATE cvs SET currency = UPPER(TRIM(currency));
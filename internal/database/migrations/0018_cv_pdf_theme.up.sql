@@ -0,0 +1 @@
+ALTER TABLE cvs ADD COLUMN IF NOT EXISTS pdf_theme VARCHAR(20) NOT NULL DEFAULT '';
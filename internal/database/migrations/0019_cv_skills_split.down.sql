@@ -0,0 +1,7 @@
+-- skills split on save can't be joined back the way they were typed, so this
+-- migration is irreversible and reverting it fails instead of leaving them split
+DO $$
+BEGIN
+	RAISE EXCEPTION 'migration 0019_cv_skills_split is irreversible';
+END
+$$;
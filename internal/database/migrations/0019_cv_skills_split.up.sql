@@ -0,0 +1,21 @@
+-- skills were saved as typed and split by spaces only when shown, now they are split
+-- on save. Existing skills are split once: by commas and semicolons when they have
+-- any, by spaces otherwise, repeated skills are dropped.
+CREATE TEMPORARY TABLE cv_skills_split ON COMMIT DROP AS
+SELECT cv_id, kind, skill, position, part
+FROM (
+	SELECT DISTINCT ON (s.cv_id, s.kind, LOWER(t.skill)) s.cv_id, s.kind, t.skill, s.position, t.part
+	FROM cv_skills s,
+		LATERAL regexp_split_to_table(
+			BTRIM(s.skill),
+			CASE WHEN s.skill ~ '[,;]' THEN '\s*[,;]\s*' ELSE '\s+' END
+		) WITH ORDINALITY AS t(skill, part)
+	WHERE t.skill <> ''
+	ORDER BY s.cv_id, s.kind, LOWER(t.skill), s.position, t.part
+) first;
+
+DELETE FROM cv_skills;
+
+INSERT INTO cv_skills (cv_id, kind, position, skill)
+SELECT cv_id, kind, ROW_NUMBER() OVER (PARTITION BY cv_id, kind ORDER BY position, part) - 1, skill
+FROM cv_skills_split;
@@ -0,0 +1,6 @@
+UPDATE cv_revisions r
+SET data = (r.data - 'photo_sha256') || jsonb_build_object('photo', encode(p.data, 'base64'))
+FROM cv_photos p
+WHERE p.cv_id = r.cv_id AND p.hash = r.data->>'photo_sha256';
+
+DROP TABLE IF EXISTS cv_photos;
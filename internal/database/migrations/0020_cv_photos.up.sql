@@ -0,0 +1,19 @@
+-- photos of revisions are kept once per CV, revision data refers to them by SHA-256
+CREATE TABLE IF NOT EXISTS cv_photos (
+	cv_id UUID REFERENCES cvs(id) ON DELETE CASCADE NOT NULL,
+	hash CHAR(64) NOT NULL,
+	data BYTEA NOT NULL,
+	PRIMARY KEY (cv_id, hash)
+);
+
+INSERT INTO cv_photos (cv_id, hash, data)
+SELECT DISTINCT ON (cv_id, hash) cv_id, hash, photo
+FROM (
+	SELECT cv_id, encode(sha256(photo), 'hex') AS hash, photo
+	FROM (SELECT cv_id, decode(data->>'photo', 'base64') AS photo FROM cv_revisions WHERE data ? 'photo') r
+) p
+ON CONFLICT DO NOTHING;
+
+UPDATE cv_revisions
+SET data = (data - 'photo') || jsonb_build_object('photo_sha256', encode(sha256(decode(data->>'photo', 'base64')), 'hex'))
+WHERE data ? 'photo';
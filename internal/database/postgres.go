@@ -35,26 +35,6 @@ func (d *DataBase) Connect(ctx context.Context) error {
 		return err
 	}
 
-	schema := `
-	CREATE TABLE IF NOT EXISTS users (
-		id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
-		name VARCHAR(20),
-		hash_password VARCHAR(70) NOT NULL,
-		email VARCHAR(30) UNIQUE NOT NULL,
-		created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP 
-	);
-	
-	CREATE TABLE IF NOT EXISTS sessions (
-		id SERIAL PRIMARY KEY,
-		user_id UUID REFERENCES users(id) NOT NULL, 
-		device_type VARCHAR(15) NOT NULL,
-		created_at TIMESTAMP   
-	)
-	`
-
-	if _, err := pool.Exec(ctx, schema); err != nil {
-		panic("Error creating migrations:" + err.Error())
-	}
 	d.pool = pool
 
 	log.Println("Database connection is valid")
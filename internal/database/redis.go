@@ -65,3 +65,63 @@ func (r *Redis) IterateWithPattern(pattern string) ([]string, error) {
 
 	return keys, nil
 }
+
+// GetMany fetches items in one round trip, missing items are returned as empty strings
+func (r *Redis) GetMany(items ...string) ([]string, error) {
+	if len(items) == 0 {
+		return nil, nil
+	}
+
+	values, err := r.rd.MGet(items...).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	data := make([]string, len(values))
+	for i, v := range values {
+		if str, ok := v.(string); ok {
+			data[i] = str
+		}
+	}
+	return data, nil
+}
+
+func (r *Redis) SetMany(data map[string]string, expTime time.Duration) error {
+	_, err := r.rd.Pipelined(func(p redis.Pipeliner) error {
+		for item, value := range data {
+			p.Set(item, value, expTime)
+		}
+		return nil
+	})
+	return err
+}
+
+func (r *Redis) Delete(items ...string) error {
+	return r.rd.Del(items...).Err()
+}
+
+func (r *Redis) SetMembers(set string) ([]string, error) {
+	return r.rd.SMembers(set).Result()
+}
+
+// ReplaceSet atomically swaps content of the set for members
+func (r *Redis) ReplaceSet(set string, expTime time.Duration, members ...string) error {
+	_, err := r.rd.TxPipelined(func(p redis.Pipeliner) error {
+		p.Del(set)
+		if len(members) == 0 {
+			return nil
+		}
+		args := make([]interface{}, len(members))
+		for i, m := range members {
+			args[i] = m
+		}
+		p.SAdd(set, args...)
+		p.Expire(set, expTime)
+		return nil
+	})
+	return err
+}
+
+func (r *Redis) GetClient() *redis.Client {
+	return r.rd
+}
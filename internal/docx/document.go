@@ -0,0 +1,112 @@
+package docx
+
+import (
+	"encoding/xml"
+	"strconv"
+	"strings"
+)
+
+// colours of text in hex as WordprocessingML wants them
+const (
+	accent  = "E68C4B"
+	muted   = "64788C"
+	expired = "C0392B"
+)
+
+// document builds body of word/document.xml, targets are urls of hyperlinks
+// in order of their relationship ids
+type document struct {
+	body    strings.Builder
+	targets []string
+}
+
+// tab moves following runs to the next tab stop of paragraph style
+const tab = "<w:r><w:tab/></w:r>"
+
+// runStyle is formatting of a run of text
+type runStyle struct {
+	bold   bool
+	italic bool
+	color  string
+}
+
+// escape makes text safe inside XML, characters not allowed in XML are replaced
+func escape(text string) string {
+	var b strings.Builder
+	xml.EscapeText(&b, []byte(text))
+	return b.String()
+}
+
+// run gives XML of text written with st
+func run(text string, st runStyle) string {
+	props := ""
+	if st.bold {
+		props += "<w:b/>"
+	}
+	if st.italic {
+		props += "<w:i/>"
+	}
+	if st.color != "" {
+		props += `<w:color w:val="` + st.color + `"/>`
+	}
+	if props != "" {
+		props = "<w:rPr>" + props + "</w:rPr>"
+	}
+	return `<w:r>` + props + `<w:t xml:space="preserve">` + escape(text) + `</w:t></w:r>`
+}
+
+// hyperlink gives XML of text linking to url and registers the link relationship
+func (d *document) hyperlink(text, url string) string {
+	d.targets = append(d.targets, url)
+	return `<w:hyperlink r:id="` + linkID(len(d.targets)-1) + `" w:history="1">` +
+		`<w:r><w:rPr><w:rStyle w:val="Hyperlink"/></w:rPr><w:t xml:space="preserve">` + escape(text) + `</w:t></w:r>` +
+		`</w:hyperlink>`
+}
+
+// linkID is relationship id of i-th link, rId1 belongs to styles
+func linkID(i int) string {
+	return "rId" + strconv.Itoa(i+2)
+}
+
+// paragraph writes paragraph of style ("" for normal text) made of runs
+func (d *document) paragraph(style string, runs ...string) {
+	d.body.WriteString("<w:p>")
+	if style != "" {
+		d.body.WriteString(`<w:pPr><w:pStyle w:val="` + style + `"/></w:pPr>`)
+	}
+	for _, r := range runs {
+		d.body.WriteString(r)
+	}
+	d.body.WriteString("</w:p>")
+}
+
+// text writes every line of text as a paragraph of style
+func (d *document) text(style, text string, st runStyle) {
+	for _, line := range strings.Split(text, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			d.paragraph(style, run(line, st))
+		}
+	}
+}
+
+// table writes rows of label and value, labels in bold on a shaded column
+func (d *document) table(rows [][2]string) {
+	const labelWidth, valueWidth = "2800", "6838"
+
+	d.body.WriteString(`<w:tbl><w:tblPr><w:tblW w:w="5000" w:type="pct"/><w:tblBorders>` +
+		`<w:top w:val="single" w:sz="4" w:space="0" w:color="DDE3EA"/>` +
+		`<w:bottom w:val="single" w:sz="4" w:space="0" w:color="DDE3EA"/>` +
+		`<w:insideH w:val="single" w:sz="4" w:space="0" w:color="DDE3EA"/>` +
+		`</w:tblBorders><w:tblCellMar><w:top w:w="60" w:type="dxa"/><w:bottom w:w="60" w:type="dxa"/></w:tblCellMar>` +
+		`</w:tblPr><w:tblGrid><w:gridCol w:w="` + labelWidth + `"/><w:gridCol w:w="` + valueWidth + `"/></w:tblGrid>`)
+
+	for _, row := range rows {
+		d.body.WriteString(`<w:tr><w:tc><w:tcPr><w:tcW w:w="` + labelWidth + `" w:type="dxa"/>` +
+			`<w:shd w:val="clear" w:color="auto" w:fill="F8F9FA"/></w:tcPr>`)
+		d.paragraph("TableText", run(row[0], runStyle{bold: true, color: accent}))
+		d.body.WriteString(`</w:tc><w:tc><w:tcPr><w:tcW w:w="` + valueWidth + `" w:type="dxa"/></w:tcPr>`)
+		d.paragraph("TableText", run(row[1], runStyle{}))
+		d.body.WriteString(`</w:tc></w:tr>`)
+	}
+	d.body.WriteString(`</w:tbl>`)
+}
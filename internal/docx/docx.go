@@ -0,0 +1,141 @@
+package docx
+
+import (
+	"archive/zip"
+	"bytes"
+	"strings"
+	"time"
+
+	ent "github.com/Vladroon22/CVmaker/internal/entity"
+)
+
+// ContentType is MIME type of documents made by Render
+const ContentType = "application/vnd.openxmlformats-officedocument.wordprocessingml.document"
+
+// Render lays out cv as A4 Word document
+func Render(cv *ent.CV) ([]byte, error) {
+	d := &document{}
+	d.header(cv)
+	d.contacts(cv)
+	d.experience(cv)
+	d.education(cv)
+	d.languages(cv)
+	d.links(cv)
+	d.projects(cv)
+	d.certificates(cv)
+	d.skills("Hard Skills", cv.HardSkills)
+	d.skills("Soft Skills", cv.SoftSkills)
+	d.about(cv)
+
+	buf := &bytes.Buffer{}
+	zw := zip.NewWriter(buf)
+
+	parts := []struct{ name, data string }{
+		{"[Content_Types].xml", contentTypes},
+		{"_rels/.rels", packageRels},
+		{"docProps/core.xml", coreProps(cv)},
+		{"word/_rels/document.xml.rels", d.rels()},
+		{"word/styles.xml", styles},
+		{"word/document.xml", documentStart + d.body.String() + documentEnd},
+	}
+	for _, part := range parts {
+		f, err := zw.Create(part.name)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := f.Write([]byte(xmlHeader + part.data)); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// rels gives relationships of document.xml, styles and then links
+func (d *document) rels() string {
+	var b strings.Builder
+	b.WriteString(`<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">`)
+	b.WriteString(`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/styles" Target="styles.xml"/>`)
+	for i, url := range d.targets {
+		b.WriteString(`<Relationship Id="` + linkID(i) + `" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/hyperlink" Target="` + escape(url) + `" TargetMode="External"/>`)
+	}
+	b.WriteString(`</Relationships>`)
+	return b.String()
+}
+
+func coreProps(cv *ent.CV) string {
+	now := time.Now().UTC().Format(time.RFC3339)
+	return `<cp:coreProperties xmlns:cp="http://schemas.openxmlformats.org/package/2006/metadata/core-properties" ` +
+		`xmlns:dc="http://purl.org/dc/elements/1.1/" xmlns:dcterms="http://purl.org/dc/terms/" ` +
+		`xmlns:xsi="http://www.w3.org/2001/XMLSchema-instance">` +
+		`<dc:title>` + escape("CV of "+cv.Name+" "+cv.Surname) + `</dc:title>` +
+		`<dc:creator>CV Maker</dc:creator>` +
+		`<dcterms:created xsi:type="dcterms:W3CDTF">` + now + `</dcterms:created>` +
+		`<dcterms:modified xsi:type="dcterms:W3CDTF">` + now + `</dcterms:modified>` +
+		`</cp:coreProperties>`
+}
+
+const xmlHeader = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>` + "\n"
+
+const contentTypes = `<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">` +
+	`<Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/>` +
+	`<Default Extension="xml" ContentType="application/xml"/>` +
+	`<Override PartName="/word/document.xml" ContentType="application/vnd.openxmlformats-officedocument.wordprocessingml.document.main+xml"/>` +
+	`<Override PartName="/word/styles.xml" ContentType="application/vnd.openxmlformats-officedocument.wordprocessingml.styles+xml"/>` +
+	`<Override PartName="/docProps/core.xml" ContentType="application/vnd.openxmlformats-package.core-properties+xml"/>` +
+	`</Types>`
+
+const packageRels = `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+	`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="word/document.xml"/>` +
+	`<Relationship Id="rId2" Type="http://schemas.openxmlformats.org/package/2006/relationships/metadata/core-properties" Target="docProps/core.xml"/>` +
+	`</Relationships>`
+
+const documentStart = `<w:document xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main" ` +
+	`xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships"><w:body>`
+
+// documentEnd closes body with A4 page and 2 cm margins, sizes are in twentieths of a point
+const documentEnd = `<w:sectPr><w:pgSz w:w="11906" w:h="16838"/>` +
+	`<w:pgMar w:top="1134" w:right="1134" w:bottom="1134" w:left="1134" w:header="567" w:footer="567" w:gutter="0"/>` +
+	`</w:sectPr></w:body></w:document>`
+
+// styles are paragraph styles used by sections, right tab of Heading2 is at the right margin
+const styles = `<w:styles xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main">` +
+	`<w:docDefaults><w:rPrDefault><w:rPr>` +
+	`<w:rFonts w:ascii="Calibri" w:hAnsi="Calibri" w:eastAsia="Calibri" w:cs="Calibri"/>` +
+	`<w:color w:val="3C4655"/><w:sz w:val="22"/><w:szCs w:val="22"/><w:lang w:val="en-US"/>` +
+	`</w:rPr></w:rPrDefault><w:pPrDefault><w:pPr><w:spacing w:after="80" w:line="264" w:lineRule="auto"/></w:pPr></w:pPrDefault></w:docDefaults>` +
+
+	`<w:style w:type="paragraph" w:default="1" w:styleId="Normal"><w:name w:val="Normal"/><w:qFormat/></w:style>` +
+
+	`<w:style w:type="paragraph" w:styleId="Title"><w:name w:val="Title"/><w:basedOn w:val="Normal"/><w:next w:val="Subtitle"/><w:qFormat/>` +
+	`<w:pPr><w:spacing w:after="40"/></w:pPr>` +
+	`<w:rPr><w:b/><w:color w:val="2C3E50"/><w:sz w:val="48"/><w:szCs w:val="48"/></w:rPr></w:style>` +
+
+	`<w:style w:type="paragraph" w:styleId="Subtitle"><w:name w:val="Subtitle"/><w:basedOn w:val="Normal"/><w:next w:val="Normal"/><w:qFormat/>` +
+	`<w:pPr><w:spacing w:after="240"/></w:pPr>` +
+	`<w:rPr><w:color w:val="E68C4B"/><w:sz w:val="28"/><w:szCs w:val="28"/></w:rPr></w:style>` +
+
+	`<w:style w:type="paragraph" w:styleId="Heading1"><w:name w:val="heading 1"/><w:basedOn w:val="Normal"/><w:next w:val="Normal"/><w:qFormat/>` +
+	`<w:pPr><w:keepNext/><w:pBdr><w:bottom w:val="single" w:sz="6" w:space="2" w:color="E68C4B"/></w:pBdr>` +
+	`<w:spacing w:before="280" w:after="120"/><w:outlineLvl w:val="0"/></w:pPr>` +
+	`<w:rPr><w:b/><w:color w:val="2C3E50"/><w:sz w:val="28"/><w:szCs w:val="28"/></w:rPr></w:style>` +
+
+	`<w:style w:type="paragraph" w:styleId="Heading2"><w:name w:val="heading 2"/><w:basedOn w:val="Normal"/><w:next w:val="Normal"/><w:qFormat/>` +
+	`<w:pPr><w:keepNext/><w:tabs><w:tab w:val="right" w:pos="9638"/></w:tabs><w:spacing w:before="160" w:after="40"/><w:outlineLvl w:val="1"/></w:pPr>` +
+	`<w:rPr><w:b/><w:color w:val="2C3E50"/><w:sz w:val="24"/><w:szCs w:val="24"/></w:rPr></w:style>` +
+
+	`<w:style w:type="paragraph" w:styleId="Bullet"><w:name w:val="Bullet"/><w:basedOn w:val="Normal"/>` +
+	`<w:pPr><w:tabs><w:tab w:val="left" w:pos="360"/></w:tabs><w:spacing w:after="40"/><w:ind w:left="360" w:hanging="240"/></w:pPr></w:style>` +
+
+	`<w:style w:type="paragraph" w:styleId="Row"><w:name w:val="Row"/><w:basedOn w:val="Normal"/>` +
+	`<w:pPr><w:tabs><w:tab w:val="left" w:pos="2800"/></w:tabs><w:spacing w:after="40"/></w:pPr></w:style>` +
+
+	`<w:style w:type="paragraph" w:styleId="TableText"><w:name w:val="Table Text"/><w:basedOn w:val="Normal"/>` +
+	`<w:pPr><w:spacing w:after="0"/><w:ind w:left="80"/></w:pPr></w:style>` +
+
+	`<w:style w:type="character" w:styleId="Hyperlink"><w:name w:val="Hyperlink"/>` +
+	`<w:rPr><w:color w:val="286EB4"/><w:u w:val="single"/></w:rPr></w:style>` +
+	`</w:styles>`
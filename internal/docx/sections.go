@@ -0,0 +1,138 @@
+package docx
+
+import (
+	"strings"
+
+	ent "github.com/Vladroon22/CVmaker/internal/entity"
+)
+
+func (d *document) header(cv *ent.CV) {
+	d.paragraph("Title", run(cv.Name+" "+cv.Surname, runStyle{}))
+	if cv.Profession != "" {
+		d.paragraph("Subtitle", run(cv.Profession, runStyle{}))
+	}
+}
+
+func (d *document) contacts(cv *ent.CV) {
+	d.paragraph("Heading1", run("Personal Information", runStyle{}))
+	d.table(cv.Contacts())
+}
+
+// entryHeading writes heading of an entry with right text like period after it,
+// heading becomes a link when url is set
+func (d *document) entryHeading(heading, right, url string) {
+	runs := []string{}
+	if url != "" {
+		runs = append(runs, d.hyperlink(heading, url))
+	} else {
+		runs = append(runs, run(heading, runStyle{}))
+	}
+	if right != "" {
+		runs = append(runs, tab, run(right, runStyle{color: accent}))
+	}
+	d.paragraph("Heading2", runs...)
+}
+
+func (d *document) experience(cv *ent.CV) {
+	if len(cv.Experience) == 0 {
+		return
+	}
+	d.paragraph("Heading1", run("Experience", runStyle{}))
+
+	for _, exp := range cv.Experience {
+		d.entryHeading(exp.Position+" · "+exp.Company, exp.Period(), "")
+		if exp.City != "" {
+			d.paragraph("", run(exp.City, runStyle{italic: true, color: muted}))
+		}
+		for _, bullet := range exp.Bullets {
+			d.paragraph("Bullet", run("•", runStyle{color: accent}), tab, run(bullet, runStyle{}))
+		}
+	}
+}
+
+func (d *document) education(cv *ent.CV) {
+	if len(cv.Education) == 0 {
+		return
+	}
+	d.paragraph("Heading1", run("Education", runStyle{}))
+
+	for _, edu := range cv.Education {
+		d.entryHeading(strings.TrimSpace(edu.Degree+" "+edu.Field), edu.Years(), "")
+		if edu.Institution != "" {
+			d.paragraph("", run(edu.Institution, runStyle{italic: true, color: muted}))
+		}
+	}
+}
+
+func (d *document) languages(cv *ent.CV) {
+	if len(cv.Languages) == 0 {
+		return
+	}
+	d.paragraph("Heading1", run("Languages", runStyle{}))
+
+	for _, lang := range cv.Languages {
+		d.paragraph("Row", run(lang.Name, runStyle{bold: true}), tab, run(lang.Level, runStyle{color: accent}))
+	}
+}
+
+func (d *document) links(cv *ent.CV) {
+	if len(cv.Links) == 0 {
+		return
+	}
+	d.paragraph("Heading1", run("Links", runStyle{}))
+
+	for _, link := range cv.Links {
+		d.paragraph("Row", run(link.Kind, runStyle{color: accent}), tab, d.hyperlink(link.Text(), link.URL))
+	}
+}
+
+func (d *document) projects(cv *ent.CV) {
+	if len(cv.Projects) == 0 {
+		return
+	}
+	d.paragraph("Heading1", run("Projects", runStyle{}))
+
+	for _, project := range cv.Projects {
+		d.entryHeading(project.Name, "", project.URL)
+		if len(project.Stack) != 0 {
+			d.paragraph("", run(strings.Join(project.Stack, " · "), runStyle{italic: true, color: muted}))
+		}
+		d.text("", project.Description, runStyle{})
+	}
+}
+
+func (d *document) certificates(cv *ent.CV) {
+	if len(cv.Certificates) == 0 {
+		return
+	}
+	d.paragraph("Heading1", run("Certifications & Awards", runStyle{}))
+
+	for _, cert := range cv.Certificates {
+		d.entryHeading(cert.Name+" · "+cert.Issuer, "", cert.URL)
+		if cert.Expired() {
+			d.paragraph("", run("EXPIRED · "+cert.Dates(), runStyle{color: expired}))
+		} else {
+			d.paragraph("", run(cert.Dates(), runStyle{color: muted}))
+		}
+		if cert.CredentialID != "" {
+			d.paragraph("", run("Credential ID: "+cert.CredentialID, runStyle{color: muted}))
+		}
+	}
+}
+
+// skills writes skills as a comma separated text
+func (d *document) skills(title string, skills []string) {
+	if len(skills) == 0 {
+		return
+	}
+	d.paragraph("Heading1", run(title, runStyle{}))
+	d.paragraph("", run(strings.Join(skills, ", "), runStyle{}))
+}
+
+func (d *document) about(cv *ent.CV) {
+	if strings.TrimSpace(cv.Description) == "" {
+		return
+	}
+	d.paragraph("Heading1", run("About Me", runStyle{}))
+	d.text("", cv.Description, runStyle{})
+}
@@ -1,6 +1,30 @@
 package entity
 
-import "time"
+import (
+	"encoding/json"
+	"errors"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Vladroon22/CVmaker/internal/currency"
+)
+
+var (
+	ErrCVNotFound = errors.New("no such CV")
+	ErrStaleCV    = errors.New("CV was changed in another tab, reload it and try again")
+	ErrNoRevision = errors.New("no such revision of CV")
+	ErrArchivedCV = errors.New("CV is archived, renew it to edit")
+
+	ErrWrongPassword = errors.New("wrong password input")
+)
+
+const (
+	StatusActive   = "active"
+	StatusExpiring = "expiring"
+	StatusArchived = "archived"
+)
 
 type UserInput struct {
 	Name     string `json:"username"`
@@ -8,20 +32,303 @@ type UserInput struct {
 	Password string `json:"password"`
 }
 
+// User is the account data given back to the user, password hash is never exposed
+type User struct {
+	ID        string    `json:"id"`
+	Name      string    `json:"name"`
+	Email     string    `json:"email"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type Session struct {
+	Device    string    `json:"device_type"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
 type CV struct {
-	ID          string   `json:"id"`
+	ID      string `json:"id"`
+	OwnerID string `json:"owner_id"`
+	Name    string `json:"name"`
+	// Age is the one saved before BirthDate was stored, use CurrentAge
+	Age        int        `json:"age"`
+	BirthDate  *time.Time `json:"birth_date,omitempty"`
+	HideAge    bool       `json:"hide_age"`
+	Profession string     `json:"profession"`
+	Surname    string     `json:"surname"`
+	EmailCV    string     `json:"emailcv"`
+	LivingCity string     `json:"city"`
+	// Salary and SalaryMax are bounds of expected salary, zero means the bound isn't set
+	Salary       int           `json:"salary"`
+	SalaryMax    int           `json:"salary_max"`
+	SalaryPeriod string        `json:"salary_period"`
+	SalaryNet    bool          `json:"salary_net"`
+	Negotiable   bool          `json:"negotiable"`
+	Currency     string        `json:"currency"`
+	PhoneNumber  string        `json:"phone"`
+	Education    EducationList `json:"education"`
+	Experience   []Experience  `json:"experience"`
+	Languages    []Language    `json:"languages"`
+	Links        []Link        `json:"links"`
+	Projects     []Project     `json:"projects"`
+	Certificates []Certificate `json:"certificates"`
+	// PDFTheme is the theme of the last PDF download, empty for the default one
+	PDFTheme string `json:"pdf_theme"`
+	// Photo is a square JPEG thumbnail, empty when CV has no photo
+	Photo       []byte    `json:"photo,omitempty"`
+	SoftSkills  []string  `json:"softskills"`
+	HardSkills  []string  `json:"hardskills"`
+	Description string    `json:"decription"`
+	Version     int       `json:"version"`
+	CreatedAt   time.Time `json:"created_at"`
+	ExpiresAt   time.Time `json:"expires_at"`
+	Status      string    `json:"status"`
+	// DeletedAt is set while CV is in trash, PurgeAt is when it will be removed for good
+	DeletedAt *time.Time `json:"deleted_at,omitempty"`
+	PurgeAt   time.Time  `json:"-"`
+	Exp       time.Time
+}
+
+// CurrentAge counts age by birth date at the moment, CVs saved without birth date give their saved Age
+func (cv CV) CurrentAge() int {
+	if cv.BirthDate == nil {
+		return cv.Age
+	}
+
+	now := time.Now()
+	age := now.Year() - cv.BirthDate.Year()
+	if now.Month() < cv.BirthDate.Month() || now.Month() == cv.BirthDate.Month() && now.Day() < cv.BirthDate.Day() {
+		age--
+	}
+	return age
+}
+
+// SalaryPeriods are periods salary is expected for, CVs saved before periods were introduced are monthly
+var SalaryPeriods = []string{"hourly", "monthly", "yearly"}
+
+// ValidSalaryPeriod reports whether period is one of SalaryPeriods
+func ValidSalaryPeriod(period string) bool {
+	for _, p := range SalaryPeriods {
+		if p == period {
+			return true
+		}
+	}
+	return false
+}
+
+// SalaryText formats salary expectation like "150 000 – 200 000 ₽ per month, gross, negotiable"
+func (cv CV) SalaryText() string {
+	var amount string
+	switch {
+	case cv.Salary != 0 && cv.SalaryMax != 0 && cv.Salary != cv.SalaryMax:
+		amount = currency.Format(cv.Salary, cv.Currency) + " – " + currency.Format(cv.SalaryMax, cv.Currency)
+	case cv.Salary != 0:
+		amount = currency.Format(cv.Salary, cv.Currency)
+		if cv.SalaryMax == 0 && cv.SalaryPeriod != "" {
+			amount = "from " + amount
+		}
+	case cv.SalaryMax != 0:
+		amount = "up to " + currency.Format(cv.SalaryMax, cv.Currency)
+	default:
+		return "Negotiable"
+	}
+
+	switch cv.SalaryPeriod {
+	case "hourly":
+		amount += " per hour"
+	case "yearly":
+		amount += " per year"
+	default:
+		amount += " per month"
+	}
+
+	if cv.SalaryNet {
+		amount += ", net"
+	} else {
+		amount += ", gross"
+	}
+	if cv.Negotiable {
+		amount += ", negotiable"
+	}
+	return amount
+}
+
+// Contacts gives personal details of CV as label and value pairs, age is left out when hidden
+func (cv CV) Contacts() [][2]string {
+	rows := [][2]string{}
+	if !cv.HideAge {
+		rows = append(rows, [2]string{"Age", strconv.Itoa(cv.CurrentAge())})
+	}
+	return append(rows,
+		[2]string{"Living City", cv.LivingCity},
+		[2]string{"Email", cv.EmailCV},
+		[2]string{"Phone", cv.PhoneNumber},
+		[2]string{"Salary Expectation", cv.SalaryText()},
+	)
+}
+
+// Experience is one job of CV, Start and End are first days of months, End is zero for the current job
+type Experience struct {
+	Company  string    `json:"company"`
+	Position string    `json:"position"`
+	City     string    `json:"city"`
+	Start    time.Time `json:"start"`
+	End      time.Time `json:"end"`
+	Current  bool      `json:"current"`
+	Bullets  []string  `json:"bullets"`
+}
+
+// Period formats months of the job like "03.2021 – now"
+func (e Experience) Period() string {
+	end := "now"
+	if !e.Current {
+		end = e.End.Format("01.2006")
+	}
+	return e.Start.Format("01.2006") + " – " + end
+}
+
+// Education is one entry of education, EndYear is zero while studying
+type Education struct {
+	Institution string `json:"institution"`
+	Degree      string `json:"degree"`
+	Field       string `json:"field"`
+	StartYear   int    `json:"start_year"`
+	EndYear     int    `json:"end_year"`
+}
+
+// Years formats years of study like "2015 – 2019", entries converted from free text have no years
+func (e Education) Years() string {
+	if e.StartYear == 0 {
+		return ""
+	}
+
+	end := "now"
+	if e.EndYear != 0 {
+		end = strconv.Itoa(e.EndYear)
+	}
+	return strconv.Itoa(e.StartYear) + " – " + end
+}
+
+type EducationList []Education
+
+// UnmarshalJSON also reads free-text education saved before entries were introduced,
+// the text becomes degree of the single entry
+func (l *EducationList) UnmarshalJSON(data []byte) error {
+	var text string
+	if err := json.Unmarshal(data, &text); err == nil {
+		*l = nil
+		if text != "" {
+			*l = EducationList{{Degree: text}}
+		}
+		return nil
+	}
+
+	var list []Education
+	if err := json.Unmarshal(data, &list); err != nil {
+		return err
+	}
+	*l = list
+	return nil
+}
+
+// LanguageLevels are CEFR levels from the lowest to native speaker
+var LanguageLevels = []string{"A1", "A2", "B1", "B2", "C1", "C2", "native"}
+
+// ValidLanguageLevel reports whether level is one of LanguageLevels
+func ValidLanguageLevel(level string) bool {
+	for _, l := range LanguageLevels {
+		if l == level {
+			return true
+		}
+	}
+	return false
+}
+
+// Language is a spoken language of CV with its proficiency from LanguageLevels
+type Language struct {
+	Name  string `json:"name"`
+	Level string `json:"level"`
+}
+
+// Bars marks which of level bars are filled, one bar per level in LanguageLevels
+func (l Language) Bars() []bool {
+	bars := make([]bool, len(LanguageLevels))
+	for i, level := range LanguageLevels {
+		bars[i] = true
+		if level == l.Level {
+			return bars
+		}
+	}
+	return make([]bool, len(LanguageLevels))
+}
+
+// LinkKinds are kinds of links user can add to CV
+var LinkKinds = []string{"github", "linkedin", "website", "portfolio", "other"}
+
+// ValidLinkKind reports whether kind is one of LinkKinds
+func ValidLinkKind(kind string) bool {
+	for _, k := range LinkKinds {
+		if k == kind {
+			return true
+		}
+	}
+	return false
+}
+
+// Link is a typed link of CV like GitHub profile, Label is optional
+type Link struct {
+	Kind  string `json:"kind"`
+	URL   string `json:"url"`
+	Label string `json:"label"`
+}
+
+// Text gives label of the link or its URL without scheme when label is empty
+func (l Link) Text() string {
+	if l.Label != "" {
+		return l.Label
+	}
+	if u, err := url.Parse(l.URL); err == nil && u.Host != "" {
+		return strings.TrimSuffix(u.Host+u.Path, "/")
+	}
+	return l.URL
+}
+
+// Project is a portfolio project of CV, URL is optional
+type Project struct {
 	Name        string   `json:"name"`
-	Age         int      `json:"age"`
-	Profession  string   `json:"profession"`
-	Surname     string   `json:"surname"`
-	EmailCV     string   `json:"emailcv"`
-	LivingCity  string   `json:"city"`
-	Salary      int      `json:"salary"`
-	Currency    string   `json:"currency"`
-	PhoneNumber string   `json:"phone"`
-	Education   string   `json:"education"`
-	SoftSkills  []string `json:"softskills"`
-	HardSkills  []string `json:"hardskills"`
-	Description string   `json:"decription"`
-	Exp         time.Time
+	Description string   `json:"description"`
+	URL         string   `json:"url"`
+	Stack       []string `json:"stack"`
+}
+
+// Certificate is a certification or an award of CV, Expires is zero when it never expires
+type Certificate struct {
+	Name         string    `json:"name"`
+	Issuer       string    `json:"issuer"`
+	CredentialID string    `json:"credential_id"`
+	Issued       time.Time `json:"issued"`
+	Expires      time.Time `json:"expires"`
+	URL          string    `json:"url"`
+}
+
+// Expired reports whether certificate is no longer valid at the moment
+func (c Certificate) Expired() bool {
+	return !c.Expires.IsZero() && c.Expires.Before(time.Now())
+}
+
+// Dates formats dates of certificate like "issued 02.01.2006, expires 02.01.2009"
+func (c Certificate) Dates() string {
+	dates := "issued " + c.Issued.Format("02.01.2006")
+	if c.Expires.IsZero() {
+		return dates
+	}
+	if c.Expired() {
+		return dates + ", expired " + c.Expires.Format("02.01.2006")
+	}
+	return dates + ", expires " + c.Expires.Format("02.01.2006")
+}
+
+type Revision struct {
+	Version   int       `json:"version"`
+	CreatedAt time.Time `json:"created_at"`
+	CV        *CV       `json:"cv,omitempty"`
 }
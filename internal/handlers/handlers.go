@@ -1,29 +1,135 @@
 package handlers
 
 import (
+	"archive/zip"
 	"context"
+	"encoding/json"
 	"errors"
+	"html/template"
+	"io"
 	"log"
 	"net/http"
-	"os"
+	"net/url"
 	"path/filepath"
+	"sort"
 	"strconv"
 	"strings"
-	"text/template"
 	"time"
 
 	"github.com/Vladroon22/CVmaker/internal/auth"
 	"github.com/Vladroon22/CVmaker/internal/cache"
+	"github.com/Vladroon22/CVmaker/internal/currency"
+	"github.com/Vladroon22/CVmaker/internal/docx"
 	ent "github.com/Vladroon22/CVmaker/internal/entity"
+	"github.com/Vladroon22/CVmaker/internal/pdf"
 	"github.com/Vladroon22/CVmaker/internal/service"
 	"github.com/Vladroon22/CVmaker/internal/utils"
-	"github.com/signintech/gopdf"
 )
 
 type PageData struct {
 	Error error
 }
 
+// RevisionsPage is data of revisions.html, Diff is filled when two revisions are compared
+type RevisionsPage struct {
+	ID         string
+	Profession string
+	Revisions  []ent.Revision
+	From       int
+	To         int
+	Diff       []utils.FieldChange
+}
+
+// ListPage is data of cv-list.html, Edit is set when the form edits existing CV
+type ListPage struct {
+	CVs  []ent.CV
+	Edit *ent.CV
+}
+
+// numbers of empty rows of list sections added to the form
+const (
+	blankExperienceRows  = 2
+	blankEducationRows   = 1
+	blankLanguageRows    = 2
+	blankLinkRows        = 2
+	blankProjectRows     = 1
+	blankCertificateRows = 1
+)
+
+// ExperienceRows gives rows of experience in the form: ones of edited CV and some blank
+func (p ListPage) ExperienceRows() []ent.Experience {
+	rows := []ent.Experience{}
+	if p.Edit != nil {
+		rows = append(rows, p.Edit.Experience...)
+	}
+	return append(rows, make([]ent.Experience, blankExperienceRows)...)
+}
+
+func (p ListPage) EducationRows() []ent.Education {
+	rows := []ent.Education{}
+	if p.Edit != nil {
+		rows = append(rows, p.Edit.Education...)
+	}
+	return append(rows, make([]ent.Education, blankEducationRows)...)
+}
+
+func (p ListPage) LanguageRows() []ent.Language {
+	rows := []ent.Language{}
+	if p.Edit != nil {
+		rows = append(rows, p.Edit.Languages...)
+	}
+	return append(rows, make([]ent.Language, blankLanguageRows)...)
+}
+
+// Currencies gives options of currency select
+func (p ListPage) Currencies() []currency.Currency {
+	return currency.List()
+}
+
+// SalaryPeriods gives options of salary period select
+func (p ListPage) SalaryPeriods() []string {
+	return ent.SalaryPeriods
+}
+
+// PDFThemes gives options of PDF theme select
+func (p ListPage) PDFThemes() []string {
+	return pdf.Names()
+}
+
+// LanguageLevels gives options of language level select
+func (p ListPage) LanguageLevels() []string {
+	return ent.LanguageLevels
+}
+
+func (p ListPage) LinkRows() []ent.Link {
+	rows := []ent.Link{}
+	if p.Edit != nil {
+		rows = append(rows, p.Edit.Links...)
+	}
+	return append(rows, make([]ent.Link, blankLinkRows)...)
+}
+
+// LinkKinds gives options of link kind select
+func (p ListPage) LinkKinds() []string {
+	return ent.LinkKinds
+}
+
+func (p ListPage) ProjectRows() []ent.Project {
+	rows := []ent.Project{}
+	if p.Edit != nil {
+		rows = append(rows, p.Edit.Projects...)
+	}
+	return append(rows, make([]ent.Project, blankProjectRows)...)
+}
+
+func (p ListPage) CertificateRows() []ent.Certificate {
+	rows := []ent.Certificate{}
+	if p.Edit != nil {
+		rows = append(rows, p.Edit.Certificates...)
+	}
+	return append(rows, make([]ent.Certificate, blankCertificateRows)...)
+}
+
 type Handlers struct {
 	srv  service.Servicer
 	cash *cache.Cache
@@ -123,8 +229,10 @@ func (h *Handlers) SignIn(w http.ResponseWriter, r *http.Request) {
 func (h *Handlers) parseCVForm(id string, r *http.Request) (*ent.CV, error) {
 	cv := &ent.CV{}
 
-	age := r.FormValue("age")
-	if !utils.ValidateDataAge(age) {
+	// birth date may be left out, checkBirthDate tells whether CV can do without it
+	age := strings.TrimSpace(r.FormValue("age"))
+	hideAge := r.FormValue("hide_age") != ""
+	if age != "" && !utils.ValidateDataAge(age) {
 		log.Println("Not valid data of birth")
 		return nil, errors.New("not valid data of birth")
 	}
@@ -141,37 +249,413 @@ func (h *Handlers) parseCVForm(id string, r *http.Request) (*ent.CV, error) {
 		return nil, errors.New("wrong email input in CV")
 	}
 
-	parts := strings.Split(age, ".")
-	day, _ := strconv.Atoi(parts[0])
-	month, _ := strconv.Atoi(parts[1])
-	year, _ := strconv.Atoi(parts[2])
-	tm := time.Date(year, time.Month(month), day, 0, 0, 0, 0, time.UTC)
+	if age != "" {
+		parts := strings.Split(age, ".")
+		day, _ := strconv.Atoi(parts[0])
+		month, _ := strconv.Atoi(parts[1])
+		year, _ := strconv.Atoi(parts[2])
+		tm := time.Date(year, time.Month(month), day, 0, 0, 0, 0, time.UTC)
+		if tm.After(time.Now()) {
+			log.Println("Date of birth in future")
+			return nil, errors.New("not valid data of birth")
+		}
+		cv.BirthDate = &tm
+	}
+
+	if err := parseSalary(r, cv); err != nil {
+		log.Println(err)
+		return nil, err
+	}
+
+	experience, err := parseExperience(r)
+	if err != nil {
+		log.Println(err)
+		return nil, err
+	}
+
+	education, err := parseEducation(r)
+	if err != nil {
+		log.Println(err)
+		return nil, err
+	}
+
+	languages, err := parseLanguages(r)
+	if err != nil {
+		log.Println(err)
+		return nil, err
+	}
+
+	links, err := parseLinks(r)
+	if err != nil {
+		log.Println(err)
+		return nil, err
+	}
+
+	projects, err := parseProjects(r)
+	if err != nil {
+		log.Println(err)
+		return nil, err
+	}
+
+	certificates, err := parseCertificates(r)
+	if err != nil {
+		log.Println(err)
+		return nil, err
+	}
 
-	salary := r.FormValue("salary")
-	salaryInt, err := strconv.Atoi(salary)
+	photo, err := parsePhoto(r)
 	if err != nil {
 		log.Println(err)
-		return nil, errors.New("salary set in wrong format")
+		return nil, err
 	}
 
-	cv.Age = utils.CountUserAge(tm)
+	cv.HideAge = hideAge
+	cv.Age = cv.CurrentAge()
 	cv.Profession = r.FormValue("profession")
 	cv.Name = r.FormValue("name")
 	cv.Surname = r.FormValue("surname")
 	cv.LivingCity = r.FormValue("city")
-	cv.Education = r.FormValue("education")
-	cv.SoftSkills = r.Form["softskills"]
-	cv.HardSkills = r.Form["hardskills"]
+	cv.Education = education
+	cv.Experience = experience
+	cv.Languages = languages
+	cv.Links = links
+	cv.Projects = projects
+	cv.Certificates = certificates
+	cv.Photo = photo
+	cv.SoftSkills = utils.ParseSkills(r.Form["softskills"]...)
+	cv.HardSkills = utils.ParseSkills(r.Form["hardskills"]...)
 	cv.Description = r.FormValue("description")
 	cv.EmailCV = email
-	cv.Salary = salaryInt
-	cv.Currency = r.FormValue("currency")
 	cv.PhoneNumber = PhoneNumber
-	cv.ID = id
+	cv.OwnerID = id
 
 	return cv, nil
 }
 
+// formRow gives trimmed value of i-th row of repeated form field name, rows the
+// browser sent fewer values for are empty
+func formRow(r *http.Request, name string, i int) string {
+	values := r.Form[name]
+	if i < len(values) {
+		return strings.TrimSpace(values[i])
+	}
+	return ""
+}
+
+// parseLinks reads rows of link_* fields, skips empty rows and keeps the order of the form
+func parseLinks(r *http.Request) ([]ent.Link, error) {
+	links := []ent.Link{}
+	for i := range r.Form["link_url"] {
+		link := ent.Link{
+			Kind:  formRow(r, "link_kind", i),
+			URL:   formRow(r, "link_url", i),
+			Label: formRow(r, "link_label", i),
+		}
+
+		if link.URL == "" && link.Label == "" {
+			continue
+		}
+		if !utils.ValidateURL(link.URL) {
+			return nil, errors.New("link " + link.URL + " must be a full http(s) URL")
+		}
+		if link.Kind == "" {
+			link.Kind = "other"
+		}
+		if !ent.ValidLinkKind(link.Kind) {
+			return nil, errors.New("kind of link must be one of " + strings.Join(ent.LinkKinds, ", "))
+		}
+
+		links = append(links, link)
+	}
+	return links, nil
+}
+
+// parseProjects reads rows of project_* fields, stack is given separated by commas
+func parseProjects(r *http.Request) ([]ent.Project, error) {
+	projects := []ent.Project{}
+	for i := range r.Form["project_name"] {
+		project := ent.Project{
+			Name:        formRow(r, "project_name", i),
+			Description: formRow(r, "project_description", i),
+			URL:         formRow(r, "project_url", i),
+			Stack:       utils.ParseSkills(formRow(r, "project_stack", i)),
+		}
+
+		if project.Name == "" && project.Description == "" && project.URL == "" && len(project.Stack) == 0 {
+			continue
+		}
+		if project.Name == "" {
+			return nil, errors.New("name of project is required")
+		}
+		if project.URL != "" && !utils.ValidateURL(project.URL) {
+			return nil, errors.New("link of project " + project.Name + " must be a full http(s) URL")
+		}
+
+		projects = append(projects, project)
+	}
+	return projects, nil
+}
+
+// parseSalary reads bounds of expected salary, its period, gross/net and currency into cv,
+// amounts may be left out only when salary is negotiable
+func parseSalary(r *http.Request, cv *ent.CV) error {
+	amount := func(name string) (int, error) {
+		value := strings.Map(func(r rune) rune {
+			if r == ' ' || r == ',' || r == '.' || r == '\u00a0' {
+				return -1
+			}
+			return r
+		}, r.FormValue(name))
+		if value == "" {
+			return 0, nil
+		}
+
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 0 {
+			return 0, errors.New("salary set in wrong format")
+		}
+		return n, nil
+	}
+
+	var err error
+	if cv.Salary, err = amount("salary"); err != nil {
+		return err
+	}
+	if cv.SalaryMax, err = amount("salary_max"); err != nil {
+		return err
+	}
+
+	cv.Negotiable = r.FormValue("negotiable") != ""
+	cv.SalaryNet = r.FormValue("salary_basis") == "net"
+
+	cv.SalaryPeriod = r.FormValue("salary_period")
+	if cv.SalaryPeriod == "" {
+		cv.SalaryPeriod = "monthly"
+	}
+	if !ent.ValidSalaryPeriod(cv.SalaryPeriod) {
+		return errors.New("period of salary must be one of " + strings.Join(ent.SalaryPeriods, ", "))
+	}
+
+	if cv.Salary == 0 && cv.SalaryMax == 0 {
+		if !cv.Negotiable {
+			return errors.New("salary is required unless it's negotiable")
+		}
+		cv.Currency = ""
+		return nil
+	}
+	if cv.SalaryMax != 0 && cv.SalaryMax < cv.Salary {
+		return errors.New("maximum salary must not be less than minimum")
+	}
+
+	cur, ok := currency.Lookup(r.FormValue("currency"))
+	if !ok {
+		return errors.New("currency must be an ISO 4217 code like USD")
+	}
+	cv.Currency = cur.Code
+	return nil
+}
+
+// parsePhoto reads uploaded photo and makes a square thumbnail of it, nil means no photo was sent
+func parsePhoto(r *http.Request) ([]byte, error) {
+	if r.MultipartForm == nil {
+		return nil, nil
+	}
+
+	file, _, err := r.FormFile("photo")
+	if errors.Is(err, http.ErrMissingFile) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, errors.New("photo sent incorrectly")
+	}
+	defer file.Close()
+
+	data, err := io.ReadAll(io.LimitReader(file, utils.MaxPhotoSize+1))
+	if err != nil {
+		return nil, errors.New("photo sent incorrectly")
+	}
+	if len(data) == 0 {
+		return nil, nil
+	}
+	return utils.MakeThumbnail(data)
+}
+
+// parseCertificates reads rows of cert_* fields, skips empty rows and sorts certificates newest first
+func parseCertificates(r *http.Request) ([]ent.Certificate, error) {
+	certificates := []ent.Certificate{}
+	for i := range r.Form["cert_name"] {
+		cert := ent.Certificate{
+			Name:         formRow(r, "cert_name", i),
+			Issuer:       formRow(r, "cert_issuer", i),
+			CredentialID: formRow(r, "cert_credential", i),
+			URL:          formRow(r, "cert_url", i),
+		}
+		issued, expires := formRow(r, "cert_issued", i), formRow(r, "cert_expires", i)
+
+		if cert.Name == "" && cert.Issuer == "" && cert.CredentialID == "" && cert.URL == "" && issued == "" && expires == "" {
+			continue
+		}
+		if cert.Name == "" || cert.Issuer == "" {
+			return nil, errors.New("name and issuer of certificate are required")
+		}
+
+		var err error
+		if cert.Issued, err = utils.ParseDate(issued); err != nil || cert.Issued.After(time.Now()) {
+			return nil, errors.New("issue date of certificate " + cert.Name + " set in wrong format")
+		}
+		if expires != "" {
+			if cert.Expires, err = utils.ParseDate(expires); err != nil {
+				return nil, errors.New("expiry date of certificate " + cert.Name + " set in wrong format")
+			}
+			if !cert.Expires.After(cert.Issued) {
+				return nil, errors.New("certificate " + cert.Name + " must expire after it's issued")
+			}
+		}
+		if cert.URL != "" && !utils.ValidateURL(cert.URL) {
+			return nil, errors.New("verification link of certificate " + cert.Name + " must be a full http(s) URL")
+		}
+
+		certificates = append(certificates, cert)
+	}
+
+	sort.SliceStable(certificates, func(i, j int) bool { return certificates[i].Issued.After(certificates[j].Issued) })
+	return certificates, nil
+}
+
+// parseLanguages reads rows of lang_* fields, skips empty rows and keeps the order of the form
+func parseLanguages(r *http.Request) ([]ent.Language, error) {
+	languages := []ent.Language{}
+	seen := map[string]bool{}
+	for i := range r.Form["lang_name"] {
+		lang := ent.Language{
+			Name:  formRow(r, "lang_name", i),
+			Level: formRow(r, "lang_level", i),
+		}
+
+		if lang.Name == "" && lang.Level == "" {
+			continue
+		}
+		if lang.Name == "" {
+			return nil, errors.New("name of language is required")
+		}
+		if !ent.ValidLanguageLevel(lang.Level) {
+			return nil, errors.New("level of " + lang.Name + " must be one of " + strings.Join(ent.LanguageLevels, ", "))
+		}
+
+		key := strings.ToLower(lang.Name)
+		if seen[key] {
+			return nil, errors.New("language " + lang.Name + " is listed twice")
+		}
+		seen[key] = true
+
+		languages = append(languages, lang)
+	}
+	return languages, nil
+}
+
+// parseEducation reads rows of edu_* fields, skips empty rows and sorts entries newest first
+func parseEducation(r *http.Request) (ent.EducationList, error) {
+	maxYear := time.Now().Year() + 10
+
+	education := ent.EducationList{}
+	for i := range r.Form["edu_institution"] {
+		edu := ent.Education{
+			Institution: formRow(r, "edu_institution", i),
+			Degree:      formRow(r, "edu_degree", i),
+			Field:       formRow(r, "edu_field", i),
+		}
+		start, end := formRow(r, "edu_start", i), formRow(r, "edu_end", i)
+
+		if edu.Institution == "" && edu.Degree == "" && edu.Field == "" && start == "" && end == "" {
+			continue
+		}
+		if edu.Institution == "" || edu.Degree == "" {
+			return nil, errors.New("institution and degree of education are required")
+		}
+
+		var err error
+		if edu.StartYear, err = strconv.Atoi(start); err != nil || edu.StartYear < 1950 || edu.StartYear > maxYear {
+			return nil, errors.New("start year of education in " + edu.Institution + " set in wrong format")
+		}
+		if end != "" {
+			if edu.EndYear, err = strconv.Atoi(end); err != nil || edu.EndYear > maxYear {
+				return nil, errors.New("end year of education in " + edu.Institution + " set in wrong format")
+			}
+			if edu.EndYear < edu.StartYear {
+				return nil, errors.New("end of education in " + edu.Institution + " must follow its start")
+			}
+		}
+
+		education = append(education, edu)
+	}
+
+	// entries without end year are still going, so they are the newest
+	sort.SliceStable(education, func(i, j int) bool {
+		a, b := education[i], education[j]
+		if (a.EndYear == 0) != (b.EndYear == 0) {
+			return a.EndYear == 0
+		}
+		if a.EndYear != b.EndYear {
+			return a.EndYear > b.EndYear
+		}
+		return a.StartYear > b.StartYear
+	})
+	return education, nil
+}
+
+// parseExperience reads rows of exp_* fields, skips empty rows and sorts jobs newest first
+func parseExperience(r *http.Request) ([]ent.Experience, error) {
+	experience := []ent.Experience{}
+	for i := range r.Form["exp_company"] {
+		exp := ent.Experience{
+			Company:  formRow(r, "exp_company", i),
+			Position: formRow(r, "exp_position", i),
+			City:     formRow(r, "exp_city", i),
+			Current:  formRow(r, "exp_current", i) != "",
+		}
+		start, end := formRow(r, "exp_start", i), formRow(r, "exp_end", i)
+		for _, line := range strings.Split(formRow(r, "exp_bullets", i), "\n") {
+			if line = strings.TrimSpace(line); line != "" {
+				exp.Bullets = append(exp.Bullets, line)
+			}
+		}
+
+		if exp.Company == "" && exp.Position == "" && exp.City == "" && start == "" && end == "" && len(exp.Bullets) == 0 {
+			continue
+		}
+		if exp.Company == "" || exp.Position == "" {
+			return nil, errors.New("company and position of experience are required")
+		}
+
+		var err error
+		if exp.Start, err = utils.ParseMonth(start); err != nil {
+			return nil, errors.New("start month of experience in " + exp.Company + " set in wrong format")
+		}
+		if !exp.Current {
+			if exp.End, err = utils.ParseMonth(end); err != nil {
+				return nil, errors.New("end month of experience in " + exp.Company + " set in wrong format")
+			}
+			if exp.End.Before(exp.Start) {
+				return nil, errors.New("end of experience in " + exp.Company + " must follow its start")
+			}
+		}
+
+		experience = append(experience, exp)
+	}
+
+	sort.SliceStable(experience, func(i, j int) bool { return experience[i].Start.After(experience[j].Start) })
+	return experience, nil
+}
+
+// checkBirthDate refuses CV showing age when it has neither birth date nor age saved before birth dates
+func checkBirthDate(cv *ent.CV) error {
+	if cv.BirthDate == nil && cv.Age == 0 && !cv.HideAge {
+		log.Println("Not valid data of birth")
+		return errors.New("not valid data of birth")
+	}
+	return nil
+}
+
 func (h *Handlers) MakeCV(w http.ResponseWriter, r *http.Request) {
 	id, err := getUserSession(r)
 	if err != nil {
@@ -190,18 +674,23 @@ func (h *Handlers) MakeCV(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
+	if err := checkBirthDate(parsedCV); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
 
-	if err := h.srv.AddNewCV(parsedCV); err != nil {
+	if err := h.srv.AddNewCV(r.Context(), parsedCV); err != nil {
 		http.Error(w, "CV's data sent incorrectly", http.StatusInternalServerError)
 		log.Println(err)
 		return
 	}
-	h.cash.Set(parsedCV.ID, parsedCV)
+	h.cash.Set(id, parsedCV)
 
 	http.Redirect(w, r, "/user/listCV", http.StatusSeeOther)
 }
 
-func (h *Handlers) ListCV(w http.ResponseWriter, r *http.Request) {
+// CloneCV copies CV under a new profession and opens the copy for editing
+func (h *Handlers) CloneCV(w http.ResponseWriter, r *http.Request) {
 	id, err := getUserSession(r)
 	if err != nil {
 		http.Redirect(w, r, "/", http.StatusSeeOther)
@@ -209,30 +698,60 @@ func (h *Handlers) ListCV(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	Profs, err := h.srv.GetProfessions(id)
+	if err := h.checkValidRequest(w, r); err != nil {
+		log.Println(err)
+		return
+	}
+
+	cvID := r.FormValue("id")
+	if cvID == "" {
+		http.Error(w, "CV id not provided", http.StatusBadRequest)
+		log.Println("CV id not provided")
+		return
+	}
+
+	clone, err := h.srv.CloneCV(r.Context(), id, cvID, r.FormValue("profession"))
 	if err != nil {
-		http.Error(w, "Profession's data got incorrectly", http.StatusInternalServerError)
+		if errors.Is(err, ent.ErrCVNotFound) {
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
+		}
+		http.Error(w, "Error of cloning CV", http.StatusInternalServerError)
 		log.Println(err)
 		return
 	}
+	h.cash.Set(id, clone)
+
+	http.Redirect(w, r, "/user/editCV?id="+url.QueryEscape(clone.ID), http.StatusSeeOther)
+}
 
-	cachedCVs := h.cash.FromToSliceByID(id)
-	if len(Profs) == 0 {
-		log.Println("No CVs in redis")
-		renderTemplate(w, "./web/cv-list.html", cachedCVs)
+func (h *Handlers) ListCV(w http.ResponseWriter, r *http.Request) {
+	id, err := getUserSession(r)
+	if err != nil {
+		http.Redirect(w, r, "/", http.StatusSeeOther)
+		log.Println(err)
 		return
 	}
 
-	if len(Profs) == h.cash.GetLen(id) {
-		log.Println("No new CVs")
-		renderTemplate(w, "./web/cv-list.html", cachedCVs)
+	cvs, err := h.listUserCVs(r.Context(), id)
+	if err != nil {
+		http.Error(w, "Profession's data got incorrectly", http.StatusInternalServerError)
+		log.Println(err)
 		return
 	}
 
-	cvs := h.handleProfessions(Profs, id)
+	renderTemplate(w, "./web/cv-list.html", ListPage{CVs: cvs})
+}
+
+func (h *Handlers) listUserCVs(c context.Context, id string) ([]ent.CV, error) {
+	cvs, err := h.srv.GetUserCVs(c, id)
+	if err != nil {
+		return nil, err
+	}
+	h.cash.SetAll(id, cvs)
 
 	log.Println("CVs: ", len(cvs))
-	renderTemplate(w, "./web/cv-list.html", cvs)
+	return cvs, nil
 }
 
 func renderTemplate(w http.ResponseWriter, templateFile string, data interface{}) {
@@ -252,36 +771,24 @@ func (h *Handlers) UserCV(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	prof := r.URL.Query().Get("profession")
-	if prof == "" {
-		http.Error(w, "Profession not provided", http.StatusBadRequest)
-		log.Println("Profession not provided")
+	cvID := r.URL.Query().Get("id")
+	if cvID == "" {
+		http.Error(w, "CV id not provided", http.StatusBadRequest)
+		log.Println("CV id not provided")
 		return
 	}
 
-	searchCV, err := h.getUserCV(id, prof)
+	searchCV, err := h.getUserCV(r.Context(), id, cvID)
 	if err != nil {
+		if errors.Is(err, ent.ErrCVNotFound) {
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
+		}
 		http.Error(w, "Error of receive data", http.StatusInternalServerError)
-		log.Println("Error of receive data from redis: ", err)
+		log.Println("Error of receive data from storage: ", err)
 		return
 	}
 
-	if len(searchCV.SoftSkills) != 0 {
-		soft := []string{}
-		for _, sk := range searchCV.SoftSkills {
-			soft = append(soft, strings.Fields(sk)...)
-		}
-		searchCV.SoftSkills = soft
-	}
-
-	if len(searchCV.HardSkills) != 0 {
-		hard := []string{}
-		for _, sk := range searchCV.HardSkills {
-			hard = append(hard, strings.Fields(sk)...)
-		}
-		searchCV.HardSkills = hard
-	}
-
 	viewHandler(w, "cv.html", searchCV)
 }
 
@@ -290,6 +797,7 @@ func (h *Handlers) LogOut(w http.ResponseWriter, r *http.Request) {
 	http.Redirect(w, r, "/", http.StatusSeeOther)
 }
 
+// DeleteCV moves CV to trash, it can be restored from /user/trash until purge
 func (h *Handlers) DeleteCV(w http.ResponseWriter, r *http.Request) {
 	id, err := getUserSession(r)
 	if err != nil {
@@ -298,45 +806,130 @@ func (h *Handlers) DeleteCV(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	prof := r.URL.Query().Get("profession")
-	if prof == "" {
-		http.Error(w, "Profession not provided", http.StatusBadRequest)
-		log.Println("Profession not provided")
+	if err := h.checkValidRequest(w, r); err != nil {
+		log.Println(err)
 		return
 	}
 
-	log.Println("prof: " + prof)
-
-	if _, ok := h.cash.Get(prof, id); !ok {
-		log.Printf("profession: %s not exists", prof)
-	} else {
-		h.cash.Delete(prof, id)
-		log.Println("deleted element with from cache")
+	cvID := r.FormValue("id")
+	if cvID == "" {
+		http.Error(w, "CV id not provided", http.StatusBadRequest)
+		log.Println("CV id not provided")
+		return
 	}
 
-	if err := h.srv.DeleteCV(id, prof); err != nil {
+	if err := h.srv.DeleteCV(r.Context(), id, cvID); err != nil {
+		if errors.Is(err, ent.ErrCVNotFound) {
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
+		}
 		http.Error(w, "error of deleting", http.StatusInternalServerError)
-		log.Printf("redis error: %v", err)
+		log.Printf("database error: %v", err)
 		return
 	}
-	log.Println("deleted element from redis")
+
+	if _, ok := h.cash.Get(cvID, id); !ok {
+		log.Printf("CV: %s not cached", cvID)
+	} else {
+		h.cash.Delete(cvID, id)
+		log.Println("deleted element with from cache")
+	}
+	log.Println("moved element to trash")
 
 	http.Redirect(w, r, "/user/listCV", http.StatusSeeOther)
-	renderTemplate(w, "./web/cv-list.html", h.cash.FromToSliceByID(id))
 }
 
-func (h *Handlers) AuthMiddleWare(next http.Handler) http.Handler {
-	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		RequestID := utils.GenRequestID()
-
-		var (
-			ID           any = "id"
-			KeyRequestID any = "X-Request-ID"
-		)
+func (h *Handlers) Trash(w http.ResponseWriter, r *http.Request) {
+	id, err := getUserSession(r)
+	if err != nil {
+		http.Redirect(w, r, "/", http.StatusSeeOther)
+		log.Println(err)
+		return
+	}
 
-		cookieJWT, err := r.Cookie("JWT")
-		if err == http.ErrNoCookie {
-			http.Error(w, "Session expired", http.StatusUnauthorized)
+	cvs, err := h.srv.ListTrash(r.Context(), id)
+	if err != nil {
+		http.Error(w, "Error of receive data", http.StatusInternalServerError)
+		log.Println(err)
+		return
+	}
+
+	renderTemplate(w, "./web/trash.html", cvs)
+}
+
+// Archive lists CVs archived on expiry, they can only be viewed or renewed there
+func (h *Handlers) Archive(w http.ResponseWriter, r *http.Request) {
+	id, err := getUserSession(r)
+	if err != nil {
+		http.Redirect(w, r, "/", http.StatusSeeOther)
+		log.Println(err)
+		return
+	}
+
+	cvs, err := h.srv.ListArchived(r.Context(), id)
+	if err != nil {
+		http.Error(w, "Error of receive data", http.StatusInternalServerError)
+		log.Println(err)
+		return
+	}
+
+	renderTemplate(w, "./web/archive.html", cvs)
+}
+
+func (h *Handlers) RestoreCV(w http.ResponseWriter, r *http.Request) {
+	h.trashAction(w, r, h.srv.RestoreCV, "/user/listCV")
+}
+
+func (h *Handlers) PurgeCV(w http.ResponseWriter, r *http.Request) {
+	h.trashAction(w, r, h.srv.PurgeCV, "/user/trash")
+}
+
+// trashAction applies action to CV from trash and redirects to back
+func (h *Handlers) trashAction(w http.ResponseWriter, r *http.Request, action func(context.Context, string, string) error, back string) {
+	id, err := getUserSession(r)
+	if err != nil {
+		http.Redirect(w, r, "/", http.StatusSeeOther)
+		log.Println(err)
+		return
+	}
+
+	if err := h.checkValidRequest(w, r); err != nil {
+		log.Println(err)
+		return
+	}
+
+	cvID := r.FormValue("id")
+	if cvID == "" {
+		http.Error(w, "CV id not provided", http.StatusBadRequest)
+		log.Println("CV id not provided")
+		return
+	}
+
+	if err := action(r.Context(), id, cvID); err != nil {
+		if errors.Is(err, ent.ErrCVNotFound) {
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
+		}
+		http.Error(w, "Error of changing trash", http.StatusInternalServerError)
+		log.Println(err)
+		return
+	}
+
+	http.Redirect(w, r, back, http.StatusSeeOther)
+}
+
+func (h *Handlers) AuthMiddleWare(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		RequestID := utils.GenRequestID()
+
+		var (
+			ID           any = "id"
+			KeyRequestID any = "X-Request-ID"
+		)
+
+		cookieJWT, err := r.Cookie("JWT")
+		if err == http.ErrNoCookie {
+			http.Error(w, "Session expired", http.StatusUnauthorized)
 			log.Println("Session expired: ", err)
 			http.Redirect(w, r, "/", http.StatusSeeOther)
 			return
@@ -358,33 +951,146 @@ func (h *Handlers) AuthMiddleWare(next http.Handler) http.Handler {
 	})
 }
 
-/*
+func (h *Handlers) EditCVPage(w http.ResponseWriter, r *http.Request) {
+	id, err := getUserSession(r)
+	if err != nil {
+		http.Redirect(w, r, "/", http.StatusSeeOther)
+		log.Println(err)
+		return
+	}
+
+	cvID := r.URL.Query().Get("id")
+	if cvID == "" {
+		http.Error(w, "CV id not provided", http.StatusBadRequest)
+		log.Println("CV id not provided")
+		return
+	}
+
+	editCV, err := h.getUserCV(r.Context(), id, cvID)
+	if err != nil {
+		if errors.Is(err, ent.ErrCVNotFound) {
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
+		}
+		http.Error(w, "Error of receive data", http.StatusInternalServerError)
+		log.Println("Error of receive data from storage: ", err)
+		return
+	}
+
+	cvs, err := h.listUserCVs(r.Context(), id)
+	if err != nil {
+		http.Error(w, "Profession's data got incorrectly", http.StatusInternalServerError)
+		log.Println(err)
+		return
+	}
+
+	renderTemplate(w, "./web/cv-list.html", ListPage{CVs: cvs, Edit: editCV})
+}
+
 func (h *Handlers) EditCV(w http.ResponseWriter, r *http.Request) {
 	id, err := getUserSession(r)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusForbidden)
+		http.Redirect(w, r, "/", http.StatusSeeOther)
+		log.Println(err)
+		return
+	}
+
+	if err := h.checkValidRequest(w, r); err != nil {
+		log.Println(err)
+		return
+	}
+
+	cvID := r.FormValue("id")
+	if cvID == "" {
+		http.Error(w, "CV id not provided", http.StatusBadRequest)
+		log.Println("CV id not provided")
+		return
+	}
+
+	version, err := strconv.Atoi(r.FormValue("version"))
+	if err != nil {
+		http.Error(w, "Version of CV not provided", http.StatusBadRequest)
+		log.Println(err)
+		return
+	}
+
+	parsedCV, err := h.parseCVForm(id, r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	parsedCV.ID = cvID
+	parsedCV.Version = version
+
+	if cv, err := h.srv.GetDataCV(r.Context(), id, cvID); err == nil {
+		// photo is sent only when it's replaced, otherwise the current one stays unless removal is asked
+		if parsedCV.Photo == nil && r.FormValue("remove_photo") == "" {
+			parsedCV.Photo = cv.Photo
+		}
+		// CVs saved before birth dates have only age, the form has no date to prefill for them
+		if parsedCV.BirthDate == nil && cv.BirthDate == nil {
+			parsedCV.Age = cv.Age
+		}
+	}
+	if err := checkBirthDate(parsedCV); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err := h.srv.UpdateCV(r.Context(), parsedCV); err != nil {
+		switch {
+		case errors.Is(err, ent.ErrStaleCV), errors.Is(err, ent.ErrArchivedCV):
+			http.Error(w, err.Error(), http.StatusConflict)
+		case errors.Is(err, ent.ErrCVNotFound):
+			http.Error(w, err.Error(), http.StatusNotFound)
+		default:
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+		log.Println(err)
+		return
+	}
+	h.cash.Replace(id, parsedCV)
+
+	http.Redirect(w, r, "/user/listCV", http.StatusSeeOther)
+}
+
+func (h *Handlers) Revisions(w http.ResponseWriter, r *http.Request) {
+	id, err := getUserSession(r)
+	if err != nil {
+		http.Redirect(w, r, "/", http.StatusSeeOther)
 		log.Println(err)
 		return
 	}
 
-	prof := r.URL.Query().Get("profession")
-	if prof == "" {
-		http.Error(w, "Profession not provided", http.StatusBadRequest)
-		log.Println("Profession not provided")
+	cvID := r.URL.Query().Get("id")
+	if cvID == "" {
+		http.Error(w, "CV id not provided", http.StatusBadRequest)
+		log.Println("CV id not provided")
 		return
 	}
 
-	_, err = h.getUserCV(id, prof)
+	cv, err := h.getUserCV(r.Context(), id, cvID)
 	if err != nil {
+		if errors.Is(err, ent.ErrCVNotFound) {
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
+		}
 		http.Error(w, "Error of receive data", http.StatusInternalServerError)
-		log.Println("Error of receive data from redis: ", err)
+		log.Println("Error of receive data from storage: ", err)
 		return
 	}
 
+	revisions, err := h.srv.ListRevisions(r.Context(), id, cvID)
+	if err != nil {
+		http.Error(w, "Error of receive data", http.StatusInternalServerError)
+		log.Println("Error of receive revisions: ", err)
+		return
+	}
+
+	renderTemplate(w, "./web/revisions.html", RevisionsPage{ID: cvID, Profession: cv.Profession, Revisions: revisions})
 }
-*/
 
-func (h *Handlers) DownloadPDF(w http.ResponseWriter, r *http.Request) {
+func (h *Handlers) ViewRevision(w http.ResponseWriter, r *http.Request) {
 	id, err := getUserSession(r)
 	if err != nil {
 		http.Redirect(w, r, "/", http.StatusSeeOther)
@@ -392,274 +1098,412 @@ func (h *Handlers) DownloadPDF(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	profession := r.URL.Query().Get("profession")
-	log.Println("Converting in pdf... ", profession)
-	if profession == "" {
-		http.Error(w, "profession provided", http.StatusBadRequest)
-		log.Println("profession not provided")
+	cvID := r.URL.Query().Get("id")
+	version, err := strconv.Atoi(r.URL.Query().Get("version"))
+	if cvID == "" || err != nil {
+		http.Error(w, "CV id or version not provided", http.StatusBadRequest)
+		log.Println("CV id or version not provided")
 		return
 	}
 
-	cv, err := h.getUserCV(id, profession)
+	rev, err := h.srv.GetRevision(r.Context(), id, cvID, version)
 	if err != nil {
+		if errors.Is(err, ent.ErrNoRevision) {
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
+		}
 		http.Error(w, "Error of receive data", http.StatusInternalServerError)
-		log.Println("Error of receive data from redis: ", err)
+		log.Println("Error of receive revision: ", err)
 		return
 	}
 
-	pdf := &gopdf.GoPdf{}
-	pdf.Start(gopdf.Config{PageSize: *gopdf.PageSizeA4})
+	viewHandler(w, "cv.html", rev.CV)
+}
 
-	pdf.AddPage()
+func (h *Handlers) DiffRevisions(w http.ResponseWriter, r *http.Request) {
+	id, err := getUserSession(r)
+	if err != nil {
+		http.Redirect(w, r, "/", http.StatusSeeOther)
+		log.Println(err)
+		return
+	}
 
-	pdf.SetFillColor(250, 250, 252)
-	pdf.Rectangle(0, 0, 595.28, 841.89, "F", 0.0, 0)
+	cvID := r.URL.Query().Get("id")
+	from, errFrom := strconv.Atoi(r.URL.Query().Get("from"))
+	to, errTo := strconv.Atoi(r.URL.Query().Get("to"))
+	if cvID == "" || errFrom != nil || errTo != nil {
+		http.Error(w, "CV id or revisions not provided", http.StatusBadRequest)
+		log.Println("CV id or revisions not provided")
+		return
+	}
 
-	pdf.SetFillColor(230, 140, 75)
-	pdf.Rectangle(0, 0, 595.28, 8, "F", 0.0, 0)
+	revisions, err := h.srv.ListRevisions(r.Context(), id, cvID)
+	if err != nil {
+		http.Error(w, "Error of receive data", http.StatusInternalServerError)
+		log.Println("Error of receive revisions: ", err)
+		return
+	}
 
-	pdf.SetFillColor(47, 69, 89)
-	pdf.Rectangle(0, 833.89, 595.28, 8, "F", 0.0, 0)
+	fromRev, err := h.srv.GetRevision(r.Context(), id, cvID, from)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		log.Println(err)
+		return
+	}
 
-	family := os.Getenv("family")
-	if err := pdf.AddTTFFont(family, os.Getenv("ttfpath")); err != nil {
-		http.Error(w, "Error of creating pdf-file", http.StatusInternalServerError)
+	toRev, err := h.srv.GetRevision(r.Context(), id, cvID, to)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
 		log.Println(err)
 		return
 	}
 
-	boldFamily := os.Getenv("family")
-	boldPath := os.Getenv("ttfpath")
-	hasBold := false
-	if boldFamily != "" && boldPath != "" {
-		if err := pdf.AddTTFFont(boldFamily, boldPath); err == nil {
-			hasBold = true
-		}
+	renderTemplate(w, "./web/revisions.html", RevisionsPage{
+		ID:         cvID,
+		Profession: toRev.CV.Profession,
+		Revisions:  revisions,
+		From:       from,
+		To:         to,
+		Diff:       utils.DiffCV(fromRev.CV, toRev.CV),
+	})
+}
+
+func (h *Handlers) RestoreRevision(w http.ResponseWriter, r *http.Request) {
+	id, err := getUserSession(r)
+	if err != nil {
+		http.Redirect(w, r, "/", http.StatusSeeOther)
+		log.Println(err)
+		return
 	}
 
-	yPos := 50.0
-	lineHeight := 22.0
-	leftMargin := 40.0
+	if err := h.checkValidRequest(w, r); err != nil {
+		log.Println(err)
+		return
+	}
 
-	addSectionTitle := func(text string) {
-		pdf.SetFillColor(230, 140, 75)
-		pdf.Rectangle(leftMargin-10, yPos-2, 5, 18, "F", 0.0, 0)
+	cvID := r.FormValue("id")
+	version, err := strconv.Atoi(r.FormValue("version"))
+	if cvID == "" || err != nil {
+		http.Error(w, "CV id or version not provided", http.StatusBadRequest)
+		log.Println("CV id or version not provided")
+		return
+	}
 
-		if hasBold {
-			pdf.SetFont(boldFamily, "", 14)
-		} else {
-			pdf.SetFont(family, "", 14)
+	restored, err := h.srv.RestoreRevision(r.Context(), id, cvID, version)
+	if err != nil {
+		switch {
+		case errors.Is(err, ent.ErrNoRevision), errors.Is(err, ent.ErrCVNotFound):
+			http.Error(w, err.Error(), http.StatusNotFound)
+		case errors.Is(err, ent.ErrStaleCV), errors.Is(err, ent.ErrArchivedCV):
+			http.Error(w, err.Error(), http.StatusConflict)
+		default:
+			http.Error(w, err.Error(), http.StatusInternalServerError)
 		}
-		pdf.SetTextColor(44, 62, 80)
-		pdf.SetX(leftMargin)
-		pdf.SetY(yPos)
-		pdf.Cell(nil, text)
-		yPos += lineHeight + 10
-		pdf.SetFont(family, "", 11)
-		pdf.SetTextColor(60, 70, 85)
+		log.Println(err)
+		return
 	}
+	h.cash.Replace(id, restored)
 
-	addInfoRow := func(label, value string) {
-		pdf.SetFillColor(248, 249, 250)
-		pdf.Rectangle(leftMargin-5, yPos-3, 500, 20, "F", 0.0, 0)
+	http.Redirect(w, r, "/user/listCV", http.StatusSeeOther)
+}
 
-		if hasBold {
-			pdf.SetFont(boldFamily, "", 11)
-		} else {
-			pdf.SetFont(family, "", 11)
+func (h *Handlers) RenewCV(w http.ResponseWriter, r *http.Request) {
+	id, err := getUserSession(r)
+	if err != nil {
+		http.Redirect(w, r, "/", http.StatusSeeOther)
+		log.Println(err)
+		return
+	}
+
+	if err := h.checkValidRequest(w, r); err != nil {
+		log.Println(err)
+		return
+	}
+
+	cvID := r.FormValue("id")
+	if cvID == "" {
+		http.Error(w, "CV id not provided", http.StatusBadRequest)
+		log.Println("CV id not provided")
+		return
+	}
+
+	until, err := h.srv.RenewCV(r.Context(), id, cvID)
+	if err != nil {
+		if errors.Is(err, ent.ErrCVNotFound) {
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
 		}
-		pdf.SetTextColor(230, 140, 75)
-		pdf.SetX(leftMargin)
-		pdf.SetY(yPos)
-		pdf.Cell(nil, label+":")
+		http.Error(w, "error of renewing", http.StatusInternalServerError)
+		log.Println(err)
+		return
+	}
+	h.cash.Delete(cvID, id)
+	log.Printf("CV %s renewed until %s", cvID, until.Format(time.DateOnly))
 
-		pdf.SetFont(family, "", 11)
-		pdf.SetTextColor(44, 62, 80)
-		pdf.SetX(leftMargin + 120)
-		pdf.SetY(yPos)
-		pdf.Cell(nil, value)
-		yPos += lineHeight + 5
+	http.Redirect(w, r, "/user/listCV", http.StatusSeeOther)
+}
+
+// DownloadPDF gives CV as PDF in the chosen theme, or as Word document with format=docx
+func (h *Handlers) DownloadPDF(w http.ResponseWriter, r *http.Request) {
+	id, err := getUserSession(r)
+	if err != nil {
+		http.Redirect(w, r, "/", http.StatusSeeOther)
+		log.Println(err)
+		return
 	}
 
-	if hasBold {
-		pdf.SetFont(boldFamily, "", 24)
-	} else {
-		pdf.SetFont(family, "", 24)
+	cvID := r.URL.Query().Get("id")
+	log.Println("Converting CV... ", cvID)
+	if cvID == "" {
+		http.Error(w, "CV id not provided", http.StatusBadRequest)
+		log.Println("CV id not provided")
+		return
 	}
-	pdf.SetTextColor(44, 62, 80)
 
-	nameText := cv.Name + " " + cv.Surname
-	nameWidth, _ := pdf.MeasureTextWidth(nameText)
-	pdf.SetX((595.28 - nameWidth) / 2)
-	pdf.SetY(yPos)
-	pdf.Cell(nil, nameText)
+	cv, err := h.getUserCV(r.Context(), id, cvID)
+	if err != nil {
+		if errors.Is(err, ent.ErrCVNotFound) {
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
+		}
+		http.Error(w, "Error of receive data", http.StatusInternalServerError)
+		log.Println("Error of receive data from storage: ", err)
+		return
+	}
 
-	pdf.SetFillColor(230, 140, 75)
-	pdf.Rectangle((595.28-100)/2, yPos+25, 100, 3, "F", 0.0, 0)
+	switch r.URL.Query().Get("format") {
+	case "", "pdf":
+	case "docx":
+		downloadDOCX(w, cv)
+		return
+	default:
+		http.Error(w, "Unknown format of CV", http.StatusBadRequest)
+		log.Println("Unknown format of CV")
+		return
+	}
 
-	yPos += 45
+	// theme chosen for the download is remembered for the next ones
+	theme := r.URL.Query().Get("theme")
+	if theme == "" {
+		theme = cv.PDFTheme
+	}
 
-	if hasBold {
-		pdf.SetFont(boldFamily, "", 16)
-	} else {
-		pdf.SetFont(family, "", 16)
+	data, err := pdf.Render(cv, theme)
+	if err != nil {
+		if errors.Is(err, pdf.ErrUnknownTheme) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+		} else {
+			http.Error(w, "Error of creating pdf-file", http.StatusInternalServerError)
+		}
+		log.Println(err)
+		return
 	}
-	pdf.SetTextColor(100, 120, 140)
-	profText := cv.Profession
-	profWidth, _ := pdf.MeasureTextWidth(profText)
-	pdf.SetX((595.28 - profWidth) / 2)
-	pdf.SetY(yPos)
-	pdf.Cell(nil, profText)
 
-	yPos += 35
+	if theme != cv.PDFTheme {
+		if err := h.srv.SetPDFTheme(r.Context(), id, cvID, theme); err != nil {
+			log.Println("Error of saving theme of pdf: ", err)
+		}
+		h.cash.Delete(cvID, id)
+	}
 
-	addSectionTitle("📋 Personal Information")
+	w.Header().Set("Content-Type", "application/pdf")
+	w.Header().Set("Content-Disposition", "attachment; filename=CV.pdf")
 
-	col1Y := yPos
-	addInfoRow("Age", strconv.Itoa(cv.Age))
-	addInfoRow("Living City", cv.LivingCity)
-	addInfoRow("Email", cv.EmailCV)
+	if _, err := w.Write(data); err != nil {
+		http.Error(w, "Error of creating pdf-file", http.StatusInternalServerError)
+		log.Println("Error writing PDF to response: ", err)
+		return
+	}
 
-	yPos = col1Y
-	leftMargin = 320.0
-	addInfoRow("Phone", cv.PhoneNumber)
-	addInfoRow("Education", cv.Education)
-	addInfoRow("Salary Expectation", strconv.Itoa(cv.Salary)+" "+cv.Currency)
+	log.Println("PDF is successfully created: CV.pdf")
+}
 
-	leftMargin = 40.0
-	yPos += 20
+// downloadDOCX writes cv as Word document for HR systems accepting only Word files
+func downloadDOCX(w http.ResponseWriter, cv *ent.CV) {
+	data, err := docx.Render(cv)
+	if err != nil {
+		http.Error(w, "Error of creating docx-file", http.StatusInternalServerError)
+		log.Println(err)
+		return
+	}
 
-	addSectionTitle("🤝 Soft Skills")
+	w.Header().Set("Content-Type", docx.ContentType)
+	w.Header().Set("Content-Disposition", "attachment; filename=CV.docx")
 
-	soft := []string{}
-	for _, sk := range cv.SoftSkills {
-		soft = append(soft, strings.Fields(sk)...)
+	if _, err := w.Write(data); err != nil {
+		log.Println("Error writing DOCX to response: ", err)
+		return
 	}
 
-	skillX := leftMargin
-	skillY := yPos
-	for i, skill := range soft {
-		if i > 0 && i%3 == 0 {
-			skillY += 25
-			skillX = leftMargin
-		}
+	log.Println("DOCX is successfully created: CV.docx")
+}
 
-		pdf.SetFillColor(240, 248, 255)
-		skillWidth := float64(len(skill)*7 + 30)
-		pdf.Rectangle(skillX, skillY, skillWidth, 20, "F", 0.0, 0)
+// Photo serves thumbnail of CV's photo
+func (h *Handlers) Photo(w http.ResponseWriter, r *http.Request) {
+	id, err := getUserSession(r)
+	if err != nil {
+		http.Redirect(w, r, "/", http.StatusSeeOther)
+		log.Println(err)
+		return
+	}
 
-		pdf.SetStrokeColor(100, 180, 220)
-		pdf.SetLineWidth(1)
-		pdf.Rectangle(skillX, skillY, skillWidth, 20, "D", 0.0, 0)
+	cvID := r.URL.Query().Get("id")
+	if cvID == "" {
+		http.Error(w, "CV id not provided", http.StatusBadRequest)
+		log.Println("CV id not provided")
+		return
+	}
 
-		pdf.SetFont(family, "", 10)
-		pdf.SetTextColor(44, 62, 80)
-		pdf.SetX(skillX + 15)
-		pdf.SetY(skillY + 4)
-		pdf.Cell(nil, skill)
+	cv, err := h.getUserCV(r.Context(), id, cvID)
+	if err != nil {
+		if errors.Is(err, ent.ErrCVNotFound) {
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
+		}
+		http.Error(w, "Error of receive data", http.StatusInternalServerError)
+		log.Println("Error of receive data from storage: ", err)
+		return
+	}
+	if len(cv.Photo) == 0 {
+		http.NotFound(w, r)
+		return
+	}
 
-		skillX += skillWidth + 10
+	w.Header().Set("Content-Type", "image/jpeg")
+	w.Header().Set("Cache-Control", "private, no-cache")
+	if _, err := w.Write(cv.Photo); err != nil {
+		log.Println("Error writing photo to response: ", err)
 	}
+}
 
-	yPos = skillY + 40
+func (h *Handlers) DeleteAccountPage(w http.ResponseWriter, r *http.Request) {
+	if _, err := getUserSession(r); err != nil {
+		http.Redirect(w, r, "/", http.StatusSeeOther)
+		log.Println(err)
+		return
+	}
 
-	addSectionTitle("🛠️ Hard Skills")
+	renderTemplate(w, "./web/delete-account.html", PageData{})
+}
 
-	hard := []string{}
-	for _, sk := range cv.HardSkills {
-		hard = append(hard, strings.Fields(sk)...)
+// DeleteAccount removes user with everything stored about them after the password is re-entered
+func (h *Handlers) DeleteAccount(w http.ResponseWriter, r *http.Request) {
+	id, err := getUserSession(r)
+	if err != nil {
+		http.Redirect(w, r, "/", http.StatusSeeOther)
+		log.Println(err)
+		return
 	}
 
-	skillX = leftMargin
-	skillY = yPos
-	for i, skill := range hard {
-		if i > 0 && i%3 == 0 {
-			skillY += 25
-			skillX = leftMargin
-		}
+	if err := h.checkValidRequest(w, r); err != nil {
+		log.Println(err)
+		return
+	}
 
-		pdf.SetFillColor(255, 248, 240)
-		skillWidth := float64(len(skill)*7 + 30)
-		pdf.Rectangle(skillX, skillY, skillWidth, 20, "F", 0.0, 0)
+	if err := h.srv.DeleteAccount(r.Context(), id, r.FormValue("password")); err != nil {
+		if errors.Is(err, ent.ErrWrongPassword) {
+			w.WriteHeader(http.StatusForbidden)
+			renderTemplate(w, "./web/delete-account.html", PageData{Error: err})
+			return
+		}
+		http.Error(w, "Error of deleting account", http.StatusInternalServerError)
+		log.Println(err)
+		return
+	}
 
-		pdf.SetStrokeColor(230, 140, 75)
-		pdf.SetLineWidth(1)
-		pdf.Rectangle(skillX, skillY, skillWidth, 20, "D", 0.0, 0)
+	h.cash.DeleteUser(id)
+	clearCookie(w, "JWT")
+	log.Printf("Account %s deleted", id)
 
-		// Текст тега
-		pdf.SetFont(family, "", 10)
-		pdf.SetTextColor(44, 62, 80)
-		pdf.SetX(skillX + 15)
-		pdf.SetY(skillY + 4)
-		pdf.Cell(nil, skill)
+	http.Redirect(w, r, "/", http.StatusSeeOther)
+}
 
-		skillX += skillWidth + 10
+// Export streams ZIP with everything stored about user: account, sessions, and every CV as JSON and PDF
+func (h *Handlers) Export(w http.ResponseWriter, r *http.Request) {
+	id, err := getUserSession(r)
+	if err != nil {
+		http.Redirect(w, r, "/", http.StatusSeeOther)
+		log.Println(err)
+		return
 	}
 
-	yPos = skillY + 50
+	user, err := h.srv.GetUser(r.Context(), id)
+	if err != nil {
+		http.Error(w, "Error of receive data", http.StatusInternalServerError)
+		log.Println(err)
+		return
+	}
 
-	if cv.Description != "" {
-		addSectionTitle("📝 About Me")
+	sessions, err := h.srv.ListSessions(r.Context(), id)
+	if err != nil {
+		http.Error(w, "Error of receive data", http.StatusInternalServerError)
+		log.Println(err)
+		return
+	}
 
-		pdf.SetFillColor(248, 249, 250)
-		pdf.Rectangle(leftMargin-5, yPos-5, 500, 80, "F", 0.0, 0)
+	cvs, err := h.srv.GetAllUserCVs(r.Context(), id)
+	if err != nil {
+		http.Error(w, "Error of receive data", http.StatusInternalServerError)
+		log.Println(err)
+		return
+	}
 
-		pdf.SetStrokeColor(200, 210, 220)
-		pdf.SetLineWidth(0.5)
-		pdf.Rectangle(leftMargin-5, yPos-5, 500, 80, "D", 0.0, 0)
+	filename := "cvmaker-export-" + time.Now().Format(time.DateOnly) + ".zip"
+	w.Header().Set("Content-Type", "application/zip")
+	w.Header().Set("Content-Disposition", "attachment; filename="+filename)
 
-		pdf.SetFont(family, "", 11)
-		pdf.SetTextColor(60, 70, 85)
-		pdf.SetX(leftMargin + 10)
-		pdf.SetY(yPos + 5)
+	// headers are sent with the first write, so from here errors only abort the archive
+	zw := zip.NewWriter(w)
 
-		words := strings.Split(cv.Description, " ")
-		lineText := ""
-		maxWidth := 470.0
+	if err := writeZipJSON(zw, "user.json", user); err != nil {
+		log.Println("export: ", err)
+		return
+	}
+	if err := writeZipJSON(zw, "sessions.json", sessions); err != nil {
+		log.Println("export: ", err)
+		return
+	}
 
-		for _, word := range words {
-			testLine := lineText
-			if testLine != "" {
-				testLine += " "
-			}
-			testLine += word
-
-			width, _ := pdf.MeasureTextWidth(testLine)
-			if width > maxWidth {
-				pdf.SetX(leftMargin + 10)
-				pdf.Cell(nil, lineText)
-				yPos += 18
-				pdf.SetY(yPos)
-				lineText = word
-			} else {
-				lineText = testLine
-			}
+	for i := range cvs {
+		cv := &cvs[i]
+		if err := writeZipJSON(zw, "cvs/"+cv.ID+".json", cv); err != nil {
+			log.Println("export: ", err)
+			return
 		}
 
-		if lineText != "" {
-			pdf.SetX(leftMargin + 10)
-			pdf.Cell(nil, lineText)
+		data, err := pdf.Render(cv, cv.PDFTheme)
+		if err != nil {
+			log.Println("export: ", err)
+			return
+		}
+		f, err := zw.Create("cvs/" + cv.ID + ".pdf")
+		if err != nil {
+			log.Println("export: ", err)
+			return
+		}
+		if _, err := f.Write(data); err != nil {
+			log.Println("export: ", err)
+			return
 		}
 	}
 
-	pdf.SetFont(family, "", 9)
-	pdf.SetTextColor(150, 160, 170)
-	footerText := "Generated by CV Maker • " + time.Now().Format("January 2, 2006")
-	footerWidth, _ := pdf.MeasureTextWidth(footerText)
-	pdf.SetX((595.28 - footerWidth) / 2)
-	pdf.SetY(810)
-	pdf.Cell(nil, footerText)
+	if err := zw.Close(); err != nil {
+		log.Println("export: ", err)
+		return
+	}
 
-	w.Header().Set("Content-Type", "application/pdf")
-	w.Header().Set("Content-Disposition", "attachment; filename=CV.pdf")
+	log.Printf("Export of user %s: %d CVs", id, len(cvs))
+}
 
-	if _, err := pdf.WriteTo(w); err != nil {
-		http.Error(w, "Error of creating pdf-file", http.StatusInternalServerError)
-		log.Println("Error writing PDF to response: ", err)
-		return
+func writeZipJSON(zw *zip.Writer, name string, v any) error {
+	f, err := zw.Create(name)
+	if err != nil {
+		return err
 	}
 
-	log.Println("PDF is successfully created: CV.pdf")
+	enc := json.NewEncoder(f)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
 }
 
 func getUserSession(r *http.Request) (string, error) {
@@ -703,55 +1547,30 @@ func clearCookie(w http.ResponseWriter, cookieName string) {
 	http.SetCookie(w, cookie)
 }
 
-func (h *Handlers) getUserCV(id string, prof string) (*ent.CV, error) {
-	searchCV, existed := h.cash.Get(prof, id)
+func (h *Handlers) getUserCV(c context.Context, id string, cvID string) (*ent.CV, error) {
+	searchCV, existed := h.cash.Get(cvID, id)
 	if !existed {
-		redisCV, err := h.srv.GetDataCV(id, prof)
+		storedCV, err := h.srv.GetDataCV(c, id, cvID)
 		if err != nil {
 			return nil, err
 		}
-		searchCV = redisCV
+		searchCV = storedCV
 	}
 	return searchCV, nil
 }
 
-func (h *Handlers) handleProfessions(Profs []string, id string) []ent.CV {
-	CVs := make([]ent.CV, 0, len(Profs))
-
-	for _, pr := range Profs {
-		if cv, ok := h.cash.Get(pr, id); ok {
-			CVs = append(CVs, *cv)
-			log.Println("CV from cache: ", cv.Profession)
-			continue
-		}
-
-		cv, err := h.srv.GetDataCV(id, pr)
-		if err != nil {
-			log.Println("Error: ", err, " fetching CV from Redis: ", pr)
-			continue
-		}
-
-		if cv == nil {
-			log.Println("Received nil CV from Redis: ", pr)
-			continue
-		}
-
-		h.cash.Set(id, cv)
-		CVs = append(CVs, *cv)
-
-		log.Println("CV from redis: ", cv.Profession)
-	}
-
-	return CVs
-}
-
 func (h *Handlers) checkValidRequest(w http.ResponseWriter, r *http.Request) error {
 	r.Body = http.MaxBytesReader(w, r.Body, 10<<20) // 10 MB
 	w.Header().Set("Content-Security-Policy", "default-src 'self'; script-src 'self';")
 
-	if err := r.ParseForm(); err != nil {
+	parse := r.ParseForm
+	if strings.HasPrefix(r.Header.Get("Content-Type"), "multipart/form-data") {
+		parse = func() error { return r.ParseMultipartForm(1 << 20) }
+	}
+
+	if err := parse(); err != nil {
 		maxBytes := &http.MaxBytesError{}
-		if err == maxBytes {
+		if errors.As(err, &maxBytes) {
 			http.Error(w, "Request body too large (max 10 MB)", http.StatusTooManyRequests)
 			log.Println("Request body too large (max 10 MB)")
 			return err
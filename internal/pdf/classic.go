@@ -0,0 +1,123 @@
+package pdf
+
+import (
+	ent "github.com/Vladroon22/CVmaker/internal/entity"
+)
+
+// classic is the original layout: centred header, framed personal information
+// in two columns and skills as tags
+type classic struct{}
+
+var classicStyle = style{
+	accent:  rgb{230, 140, 75},
+	second:  rgb{100, 180, 220},
+	title:   rgb{44, 62, 80},
+	text:    rgb{60, 70, 85},
+	muted:   rgb{100, 120, 140},
+	link:    rgb{40, 110, 180},
+	expired: rgb{192, 57, 43},
+
+	titleSize:   14,
+	headingSize: 12,
+	bodySize:    11,
+	smallSize:   10,
+	titleHeight: 32,
+	lineHeight:  16,
+	entryGap:    10,
+
+	icons:    true,
+	titleBar: true,
+}
+
+func (classic) Name() string {
+	return "classic"
+}
+
+func (classic) Render(cv *ent.CV) ([]byte, error) {
+	d, err := newDoc()
+	if err != nil {
+		return nil, err
+	}
+	st := &classicStyle
+
+	d.background = func(d *doc) {
+		d.box(0, 0, pageWidth, pageHeight, rgb{250, 250, 252}, "F")
+		d.box(0, 0, pageWidth, 8, st.accent, "F")
+		d.box(0, pageHeight-8, pageWidth, 8, rgb{47, 69, 89}, "F")
+	}
+	d.footer = func(d *doc, page, pages int) {
+		st.footer(d, 0, pageWidth, page, pages)
+	}
+
+	const left, width = 40.0, 515.0
+
+	if len(cv.Photo) != 0 {
+		if err := d.photo(cv.Photo, left+width-80, 25, 80); err != nil {
+			return nil, err
+		}
+	}
+
+	d.y = 50
+	d.font(bold, 24)
+	d.color(st.title)
+	d.textCenter(0, pageWidth, d.y, cv.Name+" "+cv.Surname)
+	d.box((pageWidth-100)/2, d.y+25, 100, 3, st.accent, "F")
+
+	d.y += 45
+	d.font(bold, 16)
+	d.color(st.muted)
+	d.textCenter(0, pageWidth, d.y, cv.Profession)
+
+	d.y += 35
+	st.sectionTitle(d, left, width, "📋", "Personal Information")
+
+	// personal information goes in two columns, the left one takes the extra row
+	rows := cv.Contacts()
+	split := (len(rows) + 1) / 2
+	top := d.y
+	leftBottom := classicInfoRows(d, st, rows[:split], left)
+	d.y = top
+	rightBottom := classicInfoRows(d, st, rows[split:], left+width/2+20)
+	d.y = max(leftBottom, rightBottom) + 20
+
+	st.experience(d, cv, left, width)
+	st.education(d, cv, left, width)
+	st.languages(d, cv, left, width)
+	st.links(d, cv, left, width)
+	st.projects(d, cv, left, width)
+	st.certificates(d, cv, left, width)
+	st.skillTags(d, left, width, "🤝", "Soft Skills", cv.SoftSkills, rgb{240, 248, 255}, st.second)
+	st.skillTags(d, left, width, "🛠️", "Hard Skills", cv.HardSkills, rgb{255, 248, 240}, st.accent)
+	st.about(d, cv, left, width, true)
+
+	return d.bytes()
+}
+
+// classicInfoRows writes label and value rows on grey background at x, values are
+// wrapped within the column, it returns the bottom of the last row
+func classicInfoRows(d *doc, st *style, rows [][2]string, x float64) float64 {
+	const columnWidth, labelWidth = 230.0, 120.0
+
+	for _, row := range rows {
+		d.font(regular, st.bodySize)
+		lines := d.lines(row[1], columnWidth-labelWidth)
+		if len(lines) == 0 {
+			lines = []string{""}
+		}
+		d.space(float64(len(lines)) * 16)
+
+		d.box(x-5, d.y-3, columnWidth+10, float64(len(lines))*16+4, rgb{248, 249, 250}, "F")
+
+		d.font(bold, st.bodySize)
+		d.color(st.accent)
+		d.text(x, d.y, row[0]+":")
+
+		d.font(regular, st.bodySize)
+		d.color(st.title)
+		for i, line := range lines {
+			d.text(x+labelWidth, d.y+float64(i)*16, line)
+		}
+		d.y += float64(len(lines))*16 + 11
+	}
+	return d.y
+}
@@ -0,0 +1,92 @@
+package pdf
+
+import (
+	"strings"
+
+	ent "github.com/Vladroon22/CVmaker/internal/entity"
+)
+
+// compact is a plain dense layout fitting more on a page: left aligned header,
+// contacts in one line and skills as text
+type compact struct{}
+
+var compactStyle = style{
+	accent:  rgb{47, 69, 89},
+	second:  rgb{100, 120, 140},
+	title:   rgb{30, 40, 50},
+	text:    rgb{50, 55, 65},
+	muted:   rgb{110, 120, 130},
+	link:    rgb{40, 110, 180},
+	expired: rgb{192, 57, 43},
+
+	titleSize:   11,
+	headingSize: 10,
+	bodySize:    9.5,
+	smallSize:   8.5,
+	titleHeight: 22,
+	lineHeight:  12,
+	entryGap:    5,
+
+	titleRule: true,
+}
+
+func (compact) Name() string {
+	return "compact"
+}
+
+func (compact) Render(cv *ent.CV) ([]byte, error) {
+	d, err := newDoc()
+	if err != nil {
+		return nil, err
+	}
+	st := &compactStyle
+
+	const left, width = 36.0, 523.0
+
+	d.top = 36
+	d.footer = func(d *doc, page, pages int) {
+		st.footer(d, left, left+width, page, pages)
+	}
+
+	textWidth := width
+	if len(cv.Photo) != 0 {
+		if err := d.photo(cv.Photo, left+width-56, 30, 56); err != nil {
+			return nil, err
+		}
+		textWidth -= 70
+	}
+
+	d.y = 32
+	d.font(bold, 20)
+	d.color(st.title)
+	d.text(left, d.y, cv.Name+" "+cv.Surname)
+
+	d.y += 26
+	d.font(regular, 12)
+	d.color(st.muted)
+	d.text(left, d.y, cv.Profession)
+
+	d.y += 20
+	details := []string{}
+	for _, row := range cv.Contacts() {
+		if row[1] != "" {
+			details = append(details, row[1])
+		}
+	}
+	d.font(regular, st.smallSize)
+	d.color(st.text)
+	d.wrap(strings.Join(details, "  ·  "), left, textWidth, st.lineHeight)
+	d.y = max(d.y, 96) + 10
+
+	st.experience(d, cv, left, width)
+	st.education(d, cv, left, width)
+	st.projects(d, cv, left, width)
+	st.certificates(d, cv, left, width)
+	st.languages(d, cv, left, width)
+	st.links(d, cv, left, width)
+	st.skillList(d, left, width, "🛠️", "Hard Skills", cv.HardSkills)
+	st.skillList(d, left, width, "🤝", "Soft Skills", cv.SoftSkills)
+	st.about(d, cv, left, width, false)
+
+	return d.bytes()
+}
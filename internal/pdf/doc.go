@@ -0,0 +1,256 @@
+package pdf
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/signintech/gopdf"
+)
+
+const (
+	pageWidth  = 595.28
+	pageHeight = 841.89
+)
+
+// rgb is a colour of text, lines or fills
+type rgb [3]uint8
+
+// doc is an A4 document being laid out by a theme, y is the top of the next line.
+//
+// Drawing is recorded per page and written out by bytes, so a theme can go back to
+// an earlier page (like two columns do) and the footer knows the number of pages.
+type doc struct {
+	pdf *gopdf.GoPdf
+	y   float64
+
+	// top and bottom bound content on every page, lines crossing bottom go to the next page
+	top    float64
+	bottom float64
+
+	// background is drawn under the content of every page, footer over it
+	background func(d *doc)
+	footer     func(d *doc, page, pages int)
+
+	page  int
+	pages [][]func() error
+
+	// state of drawing captured by recorded operations
+	face      face
+	size      float64
+	textColor rgb
+	lineWidth float64
+	stroke    rgb
+}
+
+// newDoc starts A4 document with fonts of all faces
+func newDoc() (*doc, error) {
+	pdf := &gopdf.GoPdf{}
+	pdf.Start(gopdf.Config{PageSize: *gopdf.PageSizeA4})
+
+	if err := addFonts(pdf); err != nil {
+		return nil, err
+	}
+
+	return &doc{
+		pdf:       pdf,
+		size:      10,
+		top:       40,
+		bottom:    pageHeight - 50,
+		pages:     make([][]func() error, 1),
+		lineWidth: 1,
+	}, nil
+}
+
+// draw records op on the current page
+func (d *doc) draw(op func() error) {
+	d.pages[d.page] = append(d.pages[d.page], op)
+}
+
+// space moves to the top of the next page unless h fits above the bottom
+func (d *doc) space(h float64) {
+	if d.y+h > d.bottom && d.y > d.top {
+		d.setPage(d.page + 1)
+		d.y = d.top
+	}
+}
+
+// setPage makes page (counted from 0) the current one adding pages up to it
+func (d *doc) setPage(page int) {
+	for len(d.pages) <= page {
+		d.pages = append(d.pages, nil)
+	}
+	d.page = page
+}
+
+func (d *doc) font(f face, size float64) {
+	d.face, d.size = f, size
+}
+
+func (d *doc) color(c rgb) {
+	d.textColor = c
+}
+
+// strokeStyle sets width and colour of frames drawn by box with "FD"
+func (d *doc) strokeStyle(width float64, c rgb) {
+	d.lineWidth, d.stroke = width, c
+}
+
+// box fills (and strokes with "FD") rectangle given by its upper left corner and size
+func (d *doc) box(x, y, w, h float64, fill rgb, style string) {
+	width, stroke := d.lineWidth, d.stroke
+	d.draw(func() error {
+		d.pdf.SetLineWidth(width)
+		d.pdf.SetStrokeColor(stroke[0], stroke[1], stroke[2])
+		d.pdf.SetFillColor(fill[0], fill[1], fill[2])
+		d.pdf.RectFromUpperLeftWithStyle(x, y, w, h, style)
+		return nil
+	})
+}
+
+func (d *doc) line(x1, y1, x2, y2, width float64, c rgb) {
+	d.draw(func() error {
+		d.pdf.SetStrokeColor(c[0], c[1], c[2])
+		d.pdf.SetLineWidth(width)
+		d.pdf.Line(x1, y1, x2, y2)
+		return nil
+	})
+}
+
+func (d *doc) width(text string) float64 {
+	w := 0.0
+	for _, r := range runs(d.pdf, d.face, text) {
+		w += d.runWidth(r)
+	}
+	return w
+}
+
+func (d *doc) runWidth(r run) float64 {
+	d.pdf.SetFont(r.face.String(), "", d.size)
+	w, _ := d.pdf.MeasureTextWidth(r.text)
+	return w
+}
+
+// covers tells whether all of text can be drawn, variation selectors of emoji aside
+func (d *doc) covers(text string) bool {
+	for _, r := range text {
+		if r != '\uFE0F' && !hasGlyph(d.pdf, d.face, r) && !hasGlyph(d.pdf, fallback, r) {
+			return false
+		}
+	}
+	return true
+}
+
+// text writes text with its top at x, y, glyphs missing in the current face
+// come from the fallback face
+func (d *doc) text(x, y float64, text string) {
+	size, c := d.size, d.textColor
+	for _, r := range runs(d.pdf, d.face, text) {
+		runX := x
+		x += d.runWidth(r)
+
+		d.draw(func() error {
+			if err := d.pdf.SetFont(r.face.String(), "", size); err != nil {
+				return err
+			}
+			d.pdf.SetTextColor(c[0], c[1], c[2])
+			d.pdf.SetX(runX)
+			d.pdf.SetY(y)
+			return d.pdf.Cell(nil, r.text)
+		})
+	}
+}
+
+// textRight writes text ending at right
+func (d *doc) textRight(right, y float64, text string) {
+	d.text(right-d.width(text), y, text)
+}
+
+// textCenter writes text centred between left and right
+func (d *doc) textCenter(left, right, y float64, text string) {
+	d.text(left+(right-left-d.width(text))/2, y, text)
+}
+
+// lines breaks text into lines not wider than maxWidth in the current font
+func (d *doc) lines(text string, maxWidth float64) []string {
+	lines := []string{}
+	for _, paragraph := range strings.Split(text, "\n") {
+		line := ""
+		for _, word := range strings.Fields(paragraph) {
+			next := word
+			if line != "" {
+				next = line + " " + word
+			}
+			if line != "" && d.width(next) > maxWidth {
+				lines = append(lines, line)
+				line = word
+			} else {
+				line = next
+			}
+		}
+		if line != "" {
+			lines = append(lines, line)
+		}
+	}
+	return lines
+}
+
+// wrap writes text from x at the current line breaking it by maxWidth and by pages
+func (d *doc) wrap(text string, x, maxWidth, lineHeight float64) {
+	for _, line := range d.lines(text, maxWidth) {
+		d.space(lineHeight)
+		d.text(x, d.y, line)
+		d.y += lineHeight
+	}
+}
+
+// link writes underlined text at x on the current line and makes it a clickable link to url
+func (d *doc) link(text, url string, x float64, c rgb) {
+	d.color(c)
+	d.text(x, d.y, text)
+
+	w, y := d.width(text), d.y
+	d.line(x, y+13, x+w, y+13, 0.5, c)
+	d.draw(func() error {
+		d.pdf.AddExternalLink(url, x, y, w, 14)
+		return nil
+	})
+}
+
+// photo places square photo of size with upper left corner at x, y
+func (d *doc) photo(data []byte, x, y, size float64) error {
+	holder, err := gopdf.ImageHolderByBytes(data)
+	if err != nil {
+		return err
+	}
+	d.draw(func() error {
+		return d.pdf.ImageByHolder(holder, x, y, &gopdf.Rect{W: size, H: size})
+	})
+	return nil
+}
+
+// pageNumber gives "page N of M" for the footer
+func pageNumber(page, pages int) string {
+	return "page " + strconv.Itoa(page) + " of " + strconv.Itoa(pages)
+}
+
+// bytes writes out recorded pages with their background and footer
+func (d *doc) bytes() ([]byte, error) {
+	for i, content := range d.pages {
+		d.page, d.pages[i] = i, nil
+		if d.background != nil {
+			d.background(d)
+		}
+		d.pages[i] = append(d.pages[i], content...)
+		if d.footer != nil {
+			d.footer(d, i+1, len(d.pages))
+		}
+
+		d.pdf.AddPage()
+		for _, op := range d.pages[i] {
+			if err := op(); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return d.pdf.GetBytesPdfReturnErr()
+}
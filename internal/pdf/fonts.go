@@ -0,0 +1,146 @@
+package pdf
+
+import (
+	_ "embed"
+	"os"
+	"sync"
+
+	"github.com/signintech/gopdf"
+)
+
+// face is a style of text, each face is registered in document as a font family of its own
+type face int
+
+const (
+	regular face = iota
+	bold
+	italic
+	// fallback draws glyphs missing from the face of text, it is set only by ttfFallback
+	fallback
+)
+
+var faceNames = [...]string{"regular", "bold", "italic", "fallback"}
+
+func (f face) String() string {
+	return faceNames[f]
+}
+
+// faceEnv names env holding path to TrueType file of each face
+var faceEnv = [...]string{"ttfRegular", "ttfBold", "ttfItalic", "ttfFallback"}
+
+var (
+	//go:embed fonts/DejaVuSans.ttf
+	dejaVuSans []byte
+	//go:embed fonts/DejaVuSans-Bold.ttf
+	dejaVuSansBold []byte
+	//go:embed fonts/DejaVuSans-Oblique.ttf
+	dejaVuSansOblique []byte
+)
+
+var (
+	fontsOnce sync.Once
+	fontData  [len(faceNames)][]byte
+	fontsErr  error
+
+	// glyphs caches whether face has a glyph for rune, fonts never change once loaded
+	glyphMtx sync.RWMutex
+	glyphs   = map[face]map[rune]bool{}
+)
+
+// LoadFonts reads faces from files given by ttfRegular, ttfBold, ttfItalic and ttfFallback env.
+// Regular, bold and italic without a file use embedded DejaVu Sans which covers Latin and
+// Cyrillic, there is no fallback unless it is set. Fonts are read once, later calls return
+// the first result.
+func LoadFonts() error {
+	fontsOnce.Do(func() {
+		for f, env := range faceEnv {
+			if path := os.Getenv(env); path != "" {
+				if fontData[f], fontsErr = os.ReadFile(path); fontsErr != nil {
+					return
+				}
+			}
+		}
+
+		if fontData[regular] == nil {
+			fontData[regular] = dejaVuSans
+		}
+		if fontData[bold] == nil {
+			fontData[bold] = dejaVuSansBold
+		}
+		if fontData[italic] == nil {
+			fontData[italic] = dejaVuSansOblique
+		}
+	})
+	return fontsErr
+}
+
+// addFonts registers all loaded faces in pdf
+func addFonts(pdf *gopdf.GoPdf) error {
+	if err := LoadFonts(); err != nil {
+		return err
+	}
+	for f, data := range fontData {
+		if data == nil {
+			continue
+		}
+		if err := pdf.AddTTFFontData(face(f).String(), data); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// hasGlyph tells whether face can draw r, pdf is used to look into the font.
+// A face that is not loaded draws nothing.
+func hasGlyph(pdf *gopdf.GoPdf, f face, r rune) bool {
+	if fontData[f] == nil {
+		return false
+	}
+
+	glyphMtx.RLock()
+	has, ok := glyphs[f][r]
+	glyphMtx.RUnlock()
+	if ok {
+		return has
+	}
+
+	if err := pdf.SetFont(f.String(), "", 10); err == nil {
+		has, _ = pdf.IsCurrFontContainGlyph(r)
+	}
+
+	glyphMtx.Lock()
+	if glyphs[f] == nil {
+		glyphs[f] = map[rune]bool{}
+	}
+	glyphs[f][r] = has
+	glyphMtx.Unlock()
+	return has
+}
+
+// run is a piece of text written with one face
+type run struct {
+	face face
+	text string
+}
+
+// runs splits text into pieces drawn by f or, for glyphs f lacks, by the fallback face
+// when it is set. Characters neither of them can draw (like emoji) are left out.
+func runs(pdf *gopdf.GoPdf, f face, text string) []run {
+	split := []run{}
+	for _, r := range text {
+		rf := f
+		if !hasGlyph(pdf, f, r) {
+			if !hasGlyph(pdf, fallback, r) {
+				continue
+			}
+			rf = fallback
+		}
+
+		if n := len(split); n != 0 && split[n-1].face == rf {
+			split[n-1].text += string(r)
+		} else {
+			split = append(split, run{face: rf, text: string(r)})
+		}
+	}
+	return split
+}
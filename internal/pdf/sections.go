@@ -0,0 +1,343 @@
+package pdf
+
+import (
+	"strings"
+	"time"
+
+	ent "github.com/Vladroon22/CVmaker/internal/entity"
+)
+
+// style holds colours and sizes a theme gives to shared sections
+type style struct {
+	accent, second, title, text, muted, link, expired rgb
+
+	titleSize   float64
+	headingSize float64
+	bodySize    float64
+	smallSize   float64
+	// titleHeight is the space taken by section title, lineHeight by a line of body text
+	titleHeight float64
+	lineHeight  float64
+	entryGap    float64
+
+	// icons prefixes section titles with emoji when fonts can draw them, titleBar draws accent bar before
+	// title and titleRule draws line under it across the column
+	icons     bool
+	titleBar  bool
+	titleRule bool
+}
+
+// narrow columns put labels above values instead of next to them
+const narrowColumn = 250.0
+
+// sectionTitle writes title of a section, it starts a new page when the title would
+// be left without a couple of lines of the section under it
+func (st *style) sectionTitle(d *doc, x, w float64, icon, title string) {
+	d.space(st.titleHeight + 2*st.lineHeight)
+
+	d.font(bold, st.titleSize)
+	if st.icons && d.covers(icon) {
+		title = icon + " " + title
+	}
+	if st.titleBar {
+		d.box(x-10, d.y-2, 5, 18, st.accent, "F")
+	}
+
+	d.color(st.title)
+	d.text(x, d.y, title)
+
+	if st.titleRule {
+		d.line(x, d.y+st.titleSize+3, x+w, d.y+st.titleSize+3, 0.7, st.accent)
+	}
+	d.y += st.titleHeight
+}
+
+// entryHeading writes bold heading of an entry with marker before it and right text
+// like period at the right edge of the column, heading becomes a link when url is set
+func (st *style) entryHeading(d *doc, x, w float64, heading, right, url string, marker rgb) {
+	d.space(2 * st.lineHeight)
+	d.box(x, d.y+4, 6, 6, marker, "F")
+
+	top := d.y
+	rightWidth := 0.0
+	if right != "" {
+		d.font(regular, st.smallSize)
+		rightWidth = d.width(right) + 10
+	}
+
+	d.font(bold, st.headingSize)
+	if url != "" {
+		d.link(heading, url, x+15, st.link)
+		d.y += st.lineHeight
+	} else {
+		d.color(st.text)
+		d.wrap(heading, x+15, w-15-rightWidth, st.lineHeight)
+	}
+
+	if right != "" {
+		d.font(regular, st.smallSize)
+		d.color(st.accent)
+		d.textRight(x+w, top, right)
+	}
+}
+
+func (st *style) experience(d *doc, cv *ent.CV, x, w float64) {
+	if len(cv.Experience) == 0 {
+		return
+	}
+	st.sectionTitle(d, x, w, "🏢", "Experience")
+
+	for _, exp := range cv.Experience {
+		st.entryHeading(d, x, w, exp.Position+" · "+exp.Company, exp.Period(), "", st.accent)
+
+		if exp.City != "" {
+			d.font(italic, st.smallSize)
+			d.color(st.muted)
+			d.wrap(exp.City, x+15, w-15, st.lineHeight)
+		}
+
+		d.font(regular, st.smallSize)
+		d.color(st.text)
+		for _, bullet := range exp.Bullets {
+			d.space(st.lineHeight)
+			d.text(x+20, d.y, "•")
+			d.wrap(bullet, x+32, w-32, st.lineHeight)
+		}
+
+		d.y += st.entryGap
+	}
+	d.y += st.entryGap
+}
+
+func (st *style) education(d *doc, cv *ent.CV, x, w float64) {
+	if len(cv.Education) == 0 {
+		return
+	}
+	st.sectionTitle(d, x, w, "🎓", "Education")
+
+	for _, edu := range cv.Education {
+		st.entryHeading(d, x, w, strings.TrimSpace(edu.Degree+" "+edu.Field), edu.Years(), "", st.second)
+
+		if edu.Institution != "" {
+			d.font(italic, st.smallSize)
+			d.color(st.muted)
+			d.wrap(edu.Institution, x+15, w-15, st.lineHeight)
+		}
+
+		d.y += st.entryGap
+	}
+	d.y += st.entryGap
+}
+
+func (st *style) languages(d *doc, cv *ent.CV, x, w float64) {
+	if len(cv.Languages) == 0 {
+		return
+	}
+	st.sectionTitle(d, x, w, "🗣️", "Languages")
+
+	barWidth := min(22, (w-40)/float64(len(ent.LanguageLevels))-4)
+	for _, lang := range cv.Languages {
+		d.space(2*st.lineHeight + 4)
+		d.font(regular, st.bodySize)
+		d.color(st.text)
+		d.text(x, d.y, lang.Name)
+
+		barX := x + w*0.28
+		if w < narrowColumn {
+			d.y += st.lineHeight
+			barX = x
+		}
+
+		for _, filled := range lang.Bars() {
+			fill := rgb{225, 230, 238}
+			if filled {
+				fill = st.accent
+			}
+			d.box(barX, d.y+3, barWidth, 8, fill, "F")
+			barX += barWidth + 4
+		}
+
+		d.font(regular, st.smallSize)
+		d.color(st.accent)
+		d.text(barX+6, d.y, lang.Level)
+
+		d.y += st.lineHeight + 4
+	}
+	d.y += st.entryGap
+}
+
+func (st *style) links(d *doc, cv *ent.CV, x, w float64) {
+	if len(cv.Links) == 0 {
+		return
+	}
+	st.sectionTitle(d, x, w, "🔗", "Links")
+
+	for _, link := range cv.Links {
+		d.space(2*st.lineHeight + 4)
+		d.font(regular, st.smallSize)
+		d.color(st.accent)
+		d.text(x, d.y, link.Kind)
+
+		linkX := x + 90
+		if w < narrowColumn {
+			d.y += st.lineHeight - 2
+			linkX = x
+		}
+
+		d.font(regular, st.smallSize)
+		d.link(link.Text(), link.URL, linkX, st.link)
+		d.y += st.lineHeight + 4
+	}
+	d.y += st.entryGap
+}
+
+func (st *style) projects(d *doc, cv *ent.CV, x, w float64) {
+	if len(cv.Projects) == 0 {
+		return
+	}
+	st.sectionTitle(d, x, w, "🚀", "Projects")
+
+	for _, project := range cv.Projects {
+		st.entryHeading(d, x, w, project.Name, "", project.URL, st.accent)
+
+		if len(project.Stack) != 0 {
+			d.font(italic, st.smallSize)
+			d.color(st.muted)
+			d.wrap(strings.Join(project.Stack, " · "), x+15, w-15, st.lineHeight)
+		}
+		if project.Description != "" {
+			d.font(regular, st.smallSize)
+			d.color(st.text)
+			d.wrap(project.Description, x+15, w-15, st.lineHeight)
+		}
+
+		d.y += st.entryGap
+	}
+	d.y += st.entryGap
+}
+
+func (st *style) certificates(d *doc, cv *ent.CV, x, w float64) {
+	if len(cv.Certificates) == 0 {
+		return
+	}
+	st.sectionTitle(d, x, w, "🏅", "Certifications & Awards")
+
+	for _, cert := range cv.Certificates {
+		marker := st.accent
+		if cert.Expired() {
+			marker = st.expired
+		}
+		st.entryHeading(d, x, w, cert.Name+" · "+cert.Issuer, "", cert.URL, marker)
+
+		d.font(regular, st.smallSize)
+		if cert.Expired() {
+			d.color(st.expired)
+			d.wrap("EXPIRED · "+cert.Dates(), x+15, w-15, st.lineHeight)
+		} else {
+			d.color(st.muted)
+			d.wrap(cert.Dates(), x+15, w-15, st.lineHeight)
+		}
+		if cert.CredentialID != "" {
+			d.color(st.muted)
+			d.wrap("Credential ID: "+cert.CredentialID, x+15, w-15, st.lineHeight)
+		}
+
+		d.y += st.entryGap
+	}
+	d.y += st.entryGap
+}
+
+// skillTags draws skills as framed tags sized by their text, a tag not fitting
+// before the right edge of the column goes to the next row
+func (st *style) skillTags(d *doc, x, w float64, icon, title string, skills []string, fill, stroke rgb) {
+	if len(skills) == 0 {
+		return
+	}
+	st.sectionTitle(d, x, w, icon, title)
+
+	const padding, gap, rowHeight = 10.0, 8.0, 25.0
+
+	d.font(regular, st.smallSize)
+	d.color(st.text)
+	d.strokeStyle(1, stroke)
+
+	tagX := x
+	for _, tag := range skills {
+		// a tag wider than the column is cut to fit
+		for d.width(tag)+2*padding > w && len([]rune(tag)) > 1 {
+			runes := []rune(tag)
+			tag = string(runes[:len(runes)-2]) + "…"
+		}
+		tagWidth := d.width(tag) + 2*padding
+
+		if tagX != x && tagX+tagWidth > x+w {
+			d.y += rowHeight
+			tagX = x
+		}
+		if tagX == x {
+			d.space(rowHeight)
+		}
+
+		d.box(tagX, d.y, tagWidth, 20, fill, "FD")
+		d.text(tagX+padding, d.y+4, tag)
+
+		tagX += tagWidth + gap
+	}
+
+	d.y += rowHeight + st.entryGap
+}
+
+// skillList writes skills as a comma separated text
+func (st *style) skillList(d *doc, x, w float64, icon, title string, skills []string) {
+	if len(skills) == 0 {
+		return
+	}
+	st.sectionTitle(d, x, w, icon, title)
+
+	d.font(regular, st.bodySize)
+	d.color(st.text)
+	d.wrap(strings.Join(skills, ", "), x, w, st.lineHeight)
+	d.y += st.entryGap
+}
+
+// about writes description of CV, in a framed box when boxed
+func (st *style) about(d *doc, cv *ent.CV, x, w float64, boxed bool) {
+	if cv.Description == "" {
+		return
+	}
+	st.sectionTitle(d, x, w, "📝", "About Me")
+
+	d.font(regular, st.bodySize)
+	if !boxed {
+		d.color(st.text)
+		d.wrap(cv.Description, x, w, st.lineHeight+2)
+		return
+	}
+
+	// the box is split by pages, each part framing lines fitting on its page
+	lines := d.lines(cv.Description, w-30)
+	lineHeight := st.lineHeight + 2
+	d.strokeStyle(0.5, rgb{200, 210, 220})
+	d.color(st.text)
+	for len(lines) != 0 {
+		d.space(lineHeight + 20)
+		n := min(len(lines), max(1, int((d.bottom-d.y-20)/lineHeight)))
+
+		d.box(x-5, d.y-5, w+5, float64(n)*lineHeight+20, rgb{248, 249, 250}, "FD")
+		d.y += 5
+		for _, line := range lines[:n] {
+			d.text(x+10, d.y, line)
+			d.y += lineHeight
+		}
+		d.y += 15
+		lines = lines[n:]
+	}
+}
+
+// footer writes the generation note and page number centred between left and right
+// at the bottom of the page
+func (st *style) footer(d *doc, left, right float64, page, pages int) {
+	d.font(regular, 9)
+	d.color(rgb{150, 160, 170})
+	d.textCenter(left, right, 810, "Generated by CV Maker • "+time.Now().Format("January 2, 2006")+" • "+pageNumber(page, pages))
+}
@@ -0,0 +1,77 @@
+package pdf
+
+import (
+	"errors"
+	"sync"
+
+	ent "github.com/Vladroon22/CVmaker/internal/entity"
+)
+
+// DefaultTheme is used for CVs which never chose a theme
+const DefaultTheme = "classic"
+
+var ErrUnknownTheme = errors.New("unknown theme of PDF")
+
+// Theme lays out CV as a PDF document
+type Theme interface {
+	// Name is the key of theme in the theme query parameter
+	Name() string
+	Render(cv *ent.CV) ([]byte, error)
+}
+
+var (
+	mtx    sync.RWMutex
+	themes = []Theme{classic{}, compact{}, twoColumn{}}
+)
+
+// Register adds theme or replaces the one with the same name
+func Register(theme Theme) {
+	mtx.Lock()
+	defer mtx.Unlock()
+
+	for i, t := range themes {
+		if t.Name() == theme.Name() {
+			themes[i] = theme
+			return
+		}
+	}
+	themes = append(themes, theme)
+}
+
+// Lookup finds theme by name, empty name gives DefaultTheme
+func Lookup(name string) (Theme, bool) {
+	if name == "" {
+		name = DefaultTheme
+	}
+
+	mtx.RLock()
+	defer mtx.RUnlock()
+
+	for _, t := range themes {
+		if t.Name() == name {
+			return t, true
+		}
+	}
+	return nil, false
+}
+
+// Names gives names of all themes in order of registration
+func Names() []string {
+	mtx.RLock()
+	defer mtx.RUnlock()
+
+	names := make([]string, 0, len(themes))
+	for _, t := range themes {
+		names = append(names, t.Name())
+	}
+	return names
+}
+
+// Render lays out cv with theme of the name
+func Render(cv *ent.CV, name string) ([]byte, error) {
+	theme, ok := Lookup(name)
+	if !ok {
+		return nil, ErrUnknownTheme
+	}
+	return theme.Render(cv)
+}
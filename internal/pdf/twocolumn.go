@@ -0,0 +1,128 @@
+package pdf
+
+import (
+	ent "github.com/Vladroon22/CVmaker/internal/entity"
+)
+
+// twoColumn puts photo, contacts, languages, links and skills into a dark sidebar
+// and the career into the main column
+type twoColumn struct{}
+
+var (
+	sidebarStyle = style{
+		accent:  rgb{230, 140, 75},
+		second:  rgb{100, 180, 220},
+		title:   rgb{255, 255, 255},
+		text:    rgb{225, 232, 240},
+		muted:   rgb{160, 175, 190},
+		link:    rgb{150, 200, 240},
+		expired: rgb{230, 110, 100},
+
+		titleSize:   12,
+		headingSize: 10,
+		bodySize:    9.5,
+		smallSize:   8.5,
+		titleHeight: 24,
+		lineHeight:  13,
+		entryGap:    8,
+
+		titleRule: true,
+	}
+
+	mainStyle = style{
+		accent:  rgb{230, 140, 75},
+		second:  rgb{100, 180, 220},
+		title:   rgb{44, 62, 80},
+		text:    rgb{60, 70, 85},
+		muted:   rgb{100, 120, 140},
+		link:    rgb{40, 110, 180},
+		expired: rgb{192, 57, 43},
+
+		titleSize:   13,
+		headingSize: 11,
+		bodySize:    10,
+		smallSize:   9,
+		titleHeight: 26,
+		lineHeight:  14,
+		entryGap:    8,
+
+		titleBar: true,
+	}
+)
+
+func (twoColumn) Name() string {
+	return "two-column"
+}
+
+func (twoColumn) Render(cv *ent.CV) ([]byte, error) {
+	d, err := newDoc()
+	if err != nil {
+		return nil, err
+	}
+
+	const (
+		sidebarWidth = 190.0
+		sideLeft     = 20.0
+		sideWidth    = sidebarWidth - 2*sideLeft
+		mainLeft     = sidebarWidth + 25
+		mainWidth    = pageWidth - mainLeft - 30
+	)
+
+	side, main := &sidebarStyle, &mainStyle
+
+	d.background = func(d *doc) {
+		d.box(0, 0, sidebarWidth, pageHeight, rgb{47, 69, 89}, "F")
+	}
+	d.footer = func(d *doc, page, pages int) {
+		main.footer(d, mainLeft, mainLeft+mainWidth, page, pages)
+	}
+
+	d.y = 30
+	if len(cv.Photo) != 0 {
+		const size = 110.0
+		if err := d.photo(cv.Photo, (sidebarWidth-size)/2, d.y, size); err != nil {
+			return nil, err
+		}
+		d.y += size + 20
+	}
+
+	side.sectionTitle(d, sideLeft, sideWidth, "📋", "Contacts")
+	for _, row := range cv.Contacts() {
+		d.space(2 * side.lineHeight)
+		d.font(regular, side.smallSize)
+		d.color(side.muted)
+		d.text(sideLeft, d.y, row[0])
+		d.y += side.lineHeight - 1
+
+		d.font(regular, side.bodySize)
+		d.color(side.text)
+		d.wrap(row[1], sideLeft, sideWidth, side.lineHeight)
+		d.y += 4
+	}
+	d.y += side.entryGap
+
+	side.languages(d, cv, sideLeft, sideWidth)
+	side.links(d, cv, sideLeft, sideWidth)
+	side.skillList(d, sideLeft, sideWidth, "🛠️", "Hard Skills", cv.HardSkills)
+	side.skillList(d, sideLeft, sideWidth, "🤝", "Soft Skills", cv.SoftSkills)
+
+	// the main column starts again from the first page
+	d.setPage(0)
+	d.y = 40
+	d.font(bold, 24)
+	d.color(main.title)
+	d.wrap(cv.Name+" "+cv.Surname, mainLeft, mainWidth, 28)
+
+	d.font(regular, 14)
+	d.color(main.accent)
+	d.wrap(cv.Profession, mainLeft, mainWidth, 18)
+	d.y += 20
+
+	main.experience(d, cv, mainLeft, mainWidth)
+	main.education(d, cv, mainLeft, mainWidth)
+	main.projects(d, cv, mainLeft, mainWidth)
+	main.certificates(d, cv, mainLeft, mainWidth)
+	main.about(d, cv, mainLeft, mainWidth, false)
+
+	return d.bytes()
+}
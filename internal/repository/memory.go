@@ -0,0 +1,431 @@
+package repository
+
+import (
+	"context"
+	"log"
+	"sort"
+	"sync"
+	"time"
+
+	ent "github.com/Vladroon22/CVmaker/internal/entity"
+	"github.com/Vladroon22/CVmaker/internal/utils"
+	"github.com/google/uuid"
+)
+
+// Memory keeps everything in process memory, data is lost on restart
+type Memory struct {
+	mtx       sync.RWMutex
+	users     map[string]*storedUser
+	emails    map[string]string
+	sessions  map[string][]storedSession
+	cvs       map[string]*ent.CV
+	revisions map[string][]ent.Revision
+	// tombstones maps ID of deleted user to digest of the email
+	tombstones map[string]string
+}
+
+func NewMemory() *Memory {
+	return &Memory{
+		users:      make(map[string]*storedUser),
+		emails:     make(map[string]string),
+		sessions:   make(map[string][]storedSession),
+		cvs:        make(map[string]*ent.CV),
+		revisions:  make(map[string][]ent.Revision),
+		tombstones: make(map[string]string),
+	}
+}
+
+func (m *Memory) Login(c context.Context, pass, email string) (string, error) {
+	m.mtx.RLock()
+	defer m.mtx.RUnlock()
+
+	id, ok := m.emails[email]
+	if !ok {
+		log.Println(errNoUser)
+		return "", errNoUser
+	}
+
+	if err := utils.CheckPassAndHash(m.users[id].Hash, pass); err != nil {
+		log.Println(err)
+		return "", errWrongPass
+	}
+
+	return id, nil
+}
+
+func (m *Memory) SaveSession(c context.Context, id string, device string) error {
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+
+	if len(m.sessions[id]) >= maxSessions {
+		m.sessions[id] = nil
+	}
+	m.sessions[id] = append(m.sessions[id], storedSession{Device: device, CreatedAt: time.Now().UTC()})
+
+	log.Println("User successfully log in")
+	return nil
+}
+
+func (m *Memory) CreateUser(c context.Context, user *ent.UserInput) error {
+	encPass, err := utils.Hashing(user.Password)
+	if err != nil {
+		log.Println(err)
+		return err
+	}
+
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+
+	if _, ok := m.emails[user.Email]; ok {
+		log.Println(errUserExisted)
+		return errUserExisted
+	}
+
+	id := uuid.NewString()
+	m.users[id] = &storedUser{
+		ID:        id,
+		Name:      user.Name,
+		Email:     user.Email,
+		Hash:      string(encPass),
+		CreatedAt: time.Now().UTC(),
+	}
+	m.emails[user.Email] = id
+
+	log.Println("User successfully added")
+	return nil
+}
+
+func (m *Memory) GetUser(c context.Context, id string) (*ent.User, error) {
+	m.mtx.RLock()
+	defer m.mtx.RUnlock()
+
+	user, ok := m.users[id]
+	if !ok {
+		return nil, errNoUser
+	}
+	return &ent.User{ID: user.ID, Name: user.Name, Email: user.Email, CreatedAt: user.CreatedAt}, nil
+}
+
+func (m *Memory) DeleteUser(c context.Context, id string) error {
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+
+	user, ok := m.users[id]
+	if !ok {
+		return errNoUser
+	}
+
+	for cvID, cv := range m.cvs {
+		if cv.OwnerID == id {
+			delete(m.cvs, cvID)
+			delete(m.revisions, cvID)
+		}
+	}
+	delete(m.sessions, id)
+	delete(m.emails, user.Email)
+	delete(m.users, id)
+	m.tombstones[id] = emailDigest(user.Email)
+
+	log.Println("User successfully deleted")
+	return nil
+}
+
+func (m *Memory) ListSessions(c context.Context, id string) ([]ent.Session, error) {
+	m.mtx.RLock()
+	defer m.mtx.RUnlock()
+
+	sessions := make([]ent.Session, 0, len(m.sessions[id]))
+	for _, s := range m.sessions[id] {
+		sessions = append(sessions, ent.Session{Device: s.Device, CreatedAt: s.CreatedAt})
+	}
+	return sessions, nil
+}
+
+func (m *Memory) AddNewCV(c context.Context, cv *ent.CV) error {
+	cv.ID = uuid.NewString()
+	cv.Version = 1
+	cv.CreatedAt = time.Now().UTC()
+
+	stored, err := copyCV(cv)
+	if err != nil {
+		return err
+	}
+	// revision gets a copy of its own, mutators like ExtendCV change the stored CV in place
+	snapshot, err := copyCV(cv)
+	if err != nil {
+		return err
+	}
+
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+
+	m.cvs[cv.ID] = stored
+	m.revisions[cv.ID] = append(m.revisions[cv.ID], ent.Revision{Version: cv.Version, CreatedAt: cv.CreatedAt, CV: snapshot})
+
+	log.Println("CV successfully added")
+	return nil
+}
+
+func (m *Memory) UpdateCV(c context.Context, cv *ent.CV) error {
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+
+	old, ok := m.live(cv.OwnerID, cv.ID)
+	if !ok {
+		return ent.ErrCVNotFound
+	}
+	if old.Status == ent.StatusArchived {
+		return ent.ErrArchivedCV
+	}
+	if old.Version != cv.Version {
+		return ent.ErrStaleCV
+	}
+
+	cv.Version = old.Version + 1
+	cv.CreatedAt = old.CreatedAt
+	cv.ExpiresAt = old.ExpiresAt
+	cv.Status = old.Status
+	cv.PDFTheme = old.PDFTheme
+
+	stored, err := copyCV(cv)
+	if err != nil {
+		return err
+	}
+	snapshot, err := copyCV(cv)
+	if err != nil {
+		return err
+	}
+
+	m.cvs[cv.ID] = stored
+	m.revisions[cv.ID] = append(m.revisions[cv.ID], ent.Revision{Version: cv.Version, CreatedAt: time.Now().UTC(), CV: snapshot})
+
+	log.Println("CV successfully updated")
+	return nil
+}
+
+func (m *Memory) GetUserCVs(c context.Context, id string) ([]ent.CV, error) {
+	m.mtx.RLock()
+	defer m.mtx.RUnlock()
+
+	cvs := []ent.CV{}
+	for _, cv := range m.cvs {
+		if cv.OwnerID != id || cv.Status == ent.StatusArchived || cv.DeletedAt != nil {
+			continue
+		}
+		cp, err := copyCV(cv)
+		if err != nil {
+			return nil, err
+		}
+		cvs = append(cvs, *cp)
+	}
+
+	sort.Slice(cvs, func(i, j int) bool { return cvs[i].CreatedAt.Before(cvs[j].CreatedAt) })
+	return cvs, nil
+}
+
+func (m *Memory) GetAllUserCVs(c context.Context, id string) ([]ent.CV, error) {
+	m.mtx.RLock()
+	defer m.mtx.RUnlock()
+
+	cvs := []ent.CV{}
+	for _, cv := range m.cvs {
+		if cv.OwnerID != id {
+			continue
+		}
+		cp, err := copyCV(cv)
+		if err != nil {
+			return nil, err
+		}
+		cvs = append(cvs, *cp)
+	}
+
+	sort.Slice(cvs, func(i, j int) bool { return cvs[i].CreatedAt.Before(cvs[j].CreatedAt) })
+	return cvs, nil
+}
+
+func (m *Memory) GetDataCV(c context.Context, id string, cvID string) (*ent.CV, error) {
+	m.mtx.RLock()
+	defer m.mtx.RUnlock()
+
+	cv, ok := m.live(id, cvID)
+	if !ok {
+		return nil, ent.ErrCVNotFound
+	}
+	return copyCV(cv)
+}
+
+func (m *Memory) ListRevisions(c context.Context, id, cvID string) ([]ent.Revision, error) {
+	m.mtx.RLock()
+	defer m.mtx.RUnlock()
+
+	if _, ok := m.live(id, cvID); !ok {
+		return []ent.Revision{}, nil
+	}
+
+	stored := m.revisions[cvID]
+	revisions := make([]ent.Revision, 0, len(stored))
+	for i := len(stored) - 1; i >= 0; i-- {
+		revisions = append(revisions, ent.Revision{Version: stored[i].Version, CreatedAt: stored[i].CreatedAt})
+	}
+	return revisions, nil
+}
+
+func (m *Memory) GetRevision(c context.Context, id, cvID string, version int) (*ent.Revision, error) {
+	m.mtx.RLock()
+	defer m.mtx.RUnlock()
+
+	if _, ok := m.live(id, cvID); !ok {
+		return nil, ent.ErrNoRevision
+	}
+
+	for _, rev := range m.revisions[cvID] {
+		if rev.Version == version {
+			cp, err := copyCV(rev.CV)
+			if err != nil {
+				return nil, err
+			}
+			return &ent.Revision{Version: rev.Version, CreatedAt: rev.CreatedAt, CV: cp}, nil
+		}
+	}
+	return nil, ent.ErrNoRevision
+}
+
+func (m *Memory) ExtendCV(c context.Context, id, cvID string, until time.Time) error {
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+
+	cv, ok := m.live(id, cvID)
+	if !ok {
+		return ent.ErrCVNotFound
+	}
+
+	cv.ExpiresAt = until
+	cv.Status = ent.StatusActive
+	return nil
+}
+
+func (m *Memory) SetPDFTheme(c context.Context, id, cvID, theme string) error {
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+
+	cv, ok := m.live(id, cvID)
+	if !ok {
+		return ent.ErrCVNotFound
+	}
+
+	cv.PDFTheme = theme
+	return nil
+}
+
+func (m *Memory) SweepCVs(c context.Context, now, notice time.Time, archive bool) (int, int, error) {
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+
+	expiring, expired := 0, 0
+	for cvID, cv := range m.cvs {
+		if cv.DeletedAt != nil {
+			continue
+		}
+		switch {
+		case !cv.ExpiresAt.After(now):
+			if !archive {
+				delete(m.cvs, cvID)
+				delete(m.revisions, cvID)
+				expired++
+			} else if cv.Status != ent.StatusArchived {
+				cv.Status = ent.StatusArchived
+				expired++
+			}
+		case cv.Status == ent.StatusActive && !cv.ExpiresAt.After(notice):
+			cv.Status = ent.StatusExpiring
+			expiring++
+		}
+	}
+
+	return expiring, expired, nil
+}
+
+// live returns user's CV which isn't in trash
+func (m *Memory) live(id, cvID string) (*ent.CV, bool) {
+	cv, ok := m.cvs[cvID]
+	if !ok || cv.OwnerID != id || cv.DeletedAt != nil {
+		return nil, false
+	}
+	return cv, true
+}
+
+func (m *Memory) DeleteCV(c context.Context, id, cvID string) error {
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+
+	cv, ok := m.live(id, cvID)
+	if !ok {
+		return ent.ErrCVNotFound
+	}
+
+	now := time.Now().UTC()
+	cv.DeletedAt = &now
+	return nil
+}
+
+func (m *Memory) ListTrash(c context.Context, id string) ([]ent.CV, error) {
+	m.mtx.RLock()
+	defer m.mtx.RUnlock()
+
+	cvs := []ent.CV{}
+	for _, cv := range m.cvs {
+		if cv.OwnerID != id || cv.DeletedAt == nil {
+			continue
+		}
+		cp, err := copyCV(cv)
+		if err != nil {
+			return nil, err
+		}
+		cvs = append(cvs, *cp)
+	}
+
+	sort.Slice(cvs, func(i, j int) bool { return cvs[i].DeletedAt.After(*cvs[j].DeletedAt) })
+	return cvs, nil
+}
+
+func (m *Memory) RestoreCV(c context.Context, id, cvID string) error {
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+
+	cv, ok := m.cvs[cvID]
+	if !ok || cv.OwnerID != id || cv.DeletedAt == nil {
+		return ent.ErrCVNotFound
+	}
+
+	cv.DeletedAt = nil
+	return nil
+}
+
+func (m *Memory) PurgeCV(c context.Context, id, cvID string) error {
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+
+	cv, ok := m.cvs[cvID]
+	if !ok || cv.OwnerID != id || cv.DeletedAt == nil {
+		return ent.ErrCVNotFound
+	}
+
+	delete(m.cvs, cvID)
+	delete(m.revisions, cvID)
+	return nil
+}
+
+func (m *Memory) PurgeTrash(c context.Context, before time.Time) (int, error) {
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+
+	purged := 0
+	for cvID, cv := range m.cvs {
+		if cv.DeletedAt != nil && !cv.DeletedAt.After(before) {
+			delete(m.cvs, cvID)
+			delete(m.revisions, cvID)
+			purged++
+		}
+	}
+	return purged, nil
+}
@@ -0,0 +1,1049 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"log"
+	"time"
+
+	"github.com/Vladroon22/CVmaker/internal/database"
+	ent "github.com/Vladroon22/CVmaker/internal/entity"
+	"github.com/Vladroon22/CVmaker/internal/utils"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// Postgres keeps all data in postgres, CVs are also cached in redis when it's configured
+type Postgres struct {
+	db  *database.DataBase
+	red *database.Redis
+}
+
+// NewPostgres creates postgres storage, r may be nil to work without cache
+func NewPostgres(db *database.DataBase, r *database.Redis) *Postgres {
+	return &Postgres{
+		db:  db,
+		red: r,
+	}
+}
+
+func (rp *Postgres) Login(c context.Context, pass, email string) (string, error) {
+	ctx, cancel := context.WithTimeout(c, time.Second*15)
+	defer cancel()
+
+	var (
+		id                string
+		hash, storedEmail string
+	)
+
+	pool := rp.db.GetPool()
+
+	args := pgx.NamedArgs{"email": email}
+	query := "SELECT id, email, hash_password FROM users WHERE email = @email"
+	if err := pool.QueryRow(ctx, query, args).Scan(&id, &storedEmail, &hash); err != nil {
+		if !errors.Is(err, sql.ErrNoRows) {
+			log.Println("bad resp: ", err)
+			return "", errors.New("bad response from database")
+		}
+	}
+
+	if storedEmail != email {
+		log.Println(errNoUser)
+		return "", errNoUser
+	}
+
+	if err := utils.CheckPassAndHash(hash, pass); err != nil {
+		log.Println(err)
+		return "", errWrongPass
+	}
+
+	return id, nil
+}
+
+func (rp *Postgres) SaveSession(c context.Context, id string, device string) error {
+	ctx, cancel := context.WithTimeout(c, time.Second*15)
+	defer cancel()
+
+	pool := rp.db.GetPool()
+
+	tx, errTx := pool.BeginTx(ctx, pgx.TxOptions{IsoLevel: pgx.ReadCommitted})
+	if errTx != nil {
+		log.Println("Beg Tx (session): ", errTx)
+		return errors.New("bad response from database")
+	}
+
+	defer func() {
+		errRb := tx.Rollback(ctx)
+		if errRb != nil && !errors.Is(errRb, pgx.ErrTxClosed) {
+			log.Println("Rollback Tx (session): ", errRb)
+		}
+	}()
+
+	var cnt int
+	args1 := pgx.NamedArgs{"id": id}
+	query1 := "SELECT COUNT(*) FROM sessions WHERE user_id = @id"
+	if err := tx.QueryRow(ctx, query1, args1).Scan(&cnt); err != nil {
+		log.Println("Tx to select (session): ", err)
+		return errors.New("bad response from database")
+	}
+
+	if cnt >= maxSessions {
+		args2 := pgx.NamedArgs{"id": id}
+		query2 := "DELETE FROM sessions WHERE user_id = @id"
+		if _, err := tx.Exec(ctx, query2, args2); err != nil {
+			log.Println("Tx to delete (session): ", err)
+			return errors.New("bad response from database")
+		}
+	}
+
+	now := time.Now().UTC()
+	args3 := pgx.NamedArgs{
+		"id":         id,
+		"device":     device,
+		"created_at": now,
+	}
+
+	query3 := "INSERT INTO sessions (user_id, device_type, created_at) VALUES (@id, @device, @created_at)"
+	if _, err := tx.Exec(ctx, query3, args3); err != nil {
+		log.Println("Tx to insert (session): ", errTx)
+		return errors.New("bad response from database")
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		log.Println("failed to commit tx (session): ", errTx)
+		return errors.New("bad response from database")
+	}
+
+	log.Println("User successfully log in")
+
+	return nil
+}
+
+func (rp *Postgres) CreateUser(c context.Context, user *ent.UserInput) error {
+	ctx, cancel := context.WithTimeout(c, time.Second*15)
+	defer cancel()
+
+	var emailStored string
+
+	pool := rp.db.GetPool()
+
+	tx, errTx := pool.BeginTx(ctx, pgx.TxOptions{IsoLevel: pgx.ReadCommitted})
+	if errTx != nil {
+		log.Println("Beg Tx (create user): ", errTx)
+		return errTx
+	}
+
+	defer func() {
+		errRb := tx.Rollback(ctx)
+		if errRb != nil && !errors.Is(errRb, pgx.ErrTxClosed) {
+			log.Println("Rollback Tx (session): ", errRb)
+		}
+	}()
+
+	args1 := pgx.NamedArgs{"email": user.Email}
+	query1 := "SELECT email FROM users WHERE email = @email"
+	if errRows := tx.QueryRow(ctx, query1, args1).Scan(&emailStored); errRows != nil {
+		if !errors.Is(errRows, sql.ErrNoRows) {
+			log.Println("bad resp (rows): ", errRows)
+			return errors.New("bad response from database")
+		}
+	}
+
+	if emailStored == user.Email {
+		log.Println(errUserExisted)
+		return errUserExisted
+	}
+
+	enc_pass, err := utils.Hashing(user.Password)
+	if err != nil {
+		log.Println(err)
+		return errors.New("hashing password error")
+	}
+
+	args2 := pgx.NamedArgs{
+		"name":  user.Name,
+		"email": user.Email,
+		"hash":  string(enc_pass),
+	}
+
+	query2 := "INSERT INTO users (name, email, hash_password) VALUES (@name, @email, @hash)"
+	if _, err := tx.Exec(ctx, query2, args2); err != nil {
+		log.Println("Tx to insert (create user): ", err)
+		return errors.New("bad response from database")
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		log.Println("failed to commit tx (create user): ", user.Name)
+		return errors.New("bad response from database")
+	}
+
+	log.Println("User successfully added")
+	return nil
+}
+
+func (rp *Postgres) GetUser(c context.Context, id string) (*ent.User, error) {
+	ctx, cancel := context.WithTimeout(c, time.Second*15)
+	defer cancel()
+
+	pool := rp.db.GetPool()
+
+	user := &ent.User{}
+
+	args := pgx.NamedArgs{"id": id}
+	query := "SELECT id, COALESCE(name, ''), email, created_at FROM users WHERE id = @id"
+	if err := pool.QueryRow(ctx, query, args).Scan(&user.ID, &user.Name, &user.Email, &user.CreatedAt); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, errNoUser
+		}
+		log.Println("bad resp (user): ", err)
+		return nil, errors.New("bad response from database")
+	}
+
+	return user, nil
+}
+
+// DeleteUser removes user with sessions and all CVs and leaves a tombstone with digest of the email
+func (rp *Postgres) DeleteUser(c context.Context, id string) error {
+	ctx, cancel := context.WithTimeout(c, time.Second*15)
+	defer cancel()
+
+	pool := rp.db.GetPool()
+
+	tx, errTx := pool.BeginTx(ctx, pgx.TxOptions{IsoLevel: pgx.ReadCommitted})
+	if errTx != nil {
+		log.Println("Beg Tx (delete user): ", errTx)
+		return errors.New("bad response from database")
+	}
+
+	defer func() {
+		errRb := tx.Rollback(ctx)
+		if errRb != nil && !errors.Is(errRb, pgx.ErrTxClosed) {
+			log.Println("Rollback Tx (delete user): ", errRb)
+		}
+	}()
+
+	var email string
+	args := pgx.NamedArgs{"id": id}
+	query1 := "SELECT email FROM users WHERE id = @id FOR UPDATE"
+	if err := tx.QueryRow(ctx, query1, args).Scan(&email); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return errNoUser
+		}
+		log.Println("Tx to select (delete user): ", err)
+		return errors.New("bad response from database")
+	}
+
+	query2 := "DELETE FROM cvs WHERE user_id = @id RETURNING id, user_id"
+	cvs, err := collectOwnedIDs(ctx, tx, query2, args)
+	if err != nil {
+		log.Println("Tx to delete cvs (delete user): ", err)
+		return errors.New("bad response from database")
+	}
+
+	// sessions go away with the user by ON DELETE CASCADE
+	query3 := "DELETE FROM users WHERE id = @id"
+	if _, err := tx.Exec(ctx, query3, args); err != nil {
+		log.Println("Tx to delete (delete user): ", err)
+		return errors.New("bad response from database")
+	}
+
+	args4 := pgx.NamedArgs{
+		"id":         id,
+		"email":      emailDigest(email),
+		"deleted_at": time.Now().UTC(),
+	}
+	query4 := `INSERT INTO account_tombstones (user_id, email_sha256, deleted_at) VALUES (@id, @email, @deleted_at)
+		ON CONFLICT (user_id) DO NOTHING`
+	if _, err := tx.Exec(ctx, query4, args4); err != nil {
+		log.Println("Tx to insert tombstone (delete user): ", err)
+		return errors.New("bad response from database")
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		log.Println("failed to commit tx (delete user): ", err)
+		return errors.New("bad response from database")
+	}
+
+	keys := []string{userCVsKey(id)}
+	for cvID := range cvs {
+		keys = append(keys, cvKey(cvID))
+	}
+	rp.uncache(keys...)
+
+	log.Println("User successfully deleted")
+	return nil
+}
+
+func (rp *Postgres) ListSessions(c context.Context, id string) ([]ent.Session, error) {
+	ctx, cancel := context.WithTimeout(c, time.Second*15)
+	defer cancel()
+
+	pool := rp.db.GetPool()
+
+	args := pgx.NamedArgs{"id": id}
+	query := "SELECT device_type, created_at FROM sessions WHERE user_id = @id ORDER BY created_at"
+	rows, err := pool.Query(ctx, query, args)
+	if err != nil {
+		log.Println("bad resp (sessions): ", err)
+		return nil, errors.New("bad response from database")
+	}
+
+	sessions, err := pgx.CollectRows(rows, func(row pgx.CollectableRow) (ent.Session, error) {
+		s := ent.Session{}
+		err := row.Scan(&s.Device, &s.CreatedAt)
+		return s, err
+	})
+	if err != nil {
+		log.Println("bad rows (sessions): ", err)
+		return nil, errors.New("bad response from database")
+	}
+
+	return sessions, nil
+}
+
+const (
+	softSkill = "soft"
+	hardSkill = "hard"
+)
+
+func (rp *Postgres) AddNewCV(c context.Context, cv *ent.CV) error {
+	ctx, cancel := context.WithTimeout(c, time.Second*15)
+	defer cancel()
+
+	pool := rp.db.GetPool()
+
+	tx, errTx := pool.BeginTx(ctx, pgx.TxOptions{IsoLevel: pgx.ReadCommitted})
+	if errTx != nil {
+		log.Println("Beg Tx (add cv): ", errTx)
+		return errors.New("bad response from database")
+	}
+
+	defer func() {
+		errRb := tx.Rollback(ctx)
+		if errRb != nil && !errors.Is(errRb, pgx.ErrTxClosed) {
+			log.Println("Rollback Tx (add cv): ", errRb)
+		}
+	}()
+
+	cv.ID = uuid.NewString()
+	cv.Version = 1
+	cv.CreatedAt = time.Now().UTC()
+
+	args1 := pgx.NamedArgs{
+		"id":            cv.ID,
+		"user_id":       cv.OwnerID,
+		"version":       cv.Version,
+		"profession":    cv.Profession,
+		"name":          cv.Name,
+		"surname":       cv.Surname,
+		"age":           cv.Age,
+		"birth_date":    cv.BirthDate,
+		"hide_age":      cv.HideAge,
+		"email":         cv.EmailCV,
+		"city":          cv.LivingCity,
+		"salary":        cv.Salary,
+		"salary_max":    cv.SalaryMax,
+		"salary_period": cv.SalaryPeriod,
+		"salary_net":    cv.SalaryNet,
+		"negotiable":    cv.Negotiable,
+		"currency":      cv.Currency,
+		"phone":         cv.PhoneNumber,
+		"education":     jsonList(cv.Education),
+		"experience":    jsonList(cv.Experience),
+		"languages":     jsonList(cv.Languages),
+		"links":         jsonList(cv.Links),
+		"projects":      jsonList(cv.Projects),
+		"certificates":  jsonList(cv.Certificates),
+		"photo":         cv.Photo,
+		"pdf_theme":     cv.PDFTheme,
+		"description":   cv.Description,
+		"created_at":    cv.CreatedAt,
+		"updated_at":    cv.CreatedAt,
+		"expires_at":    cv.ExpiresAt,
+		"status":        cv.Status,
+	}
+
+	query1 := `INSERT INTO cvs (id, user_id, version, profession, name, surname, age, birth_date, hide_age, email, city, salary, salary_max, salary_period, salary_net, negotiable, currency, phone, education, experience, languages, links, projects, certificates, photo, pdf_theme, description, created_at, updated_at, expires_at, status)
+		VALUES (@id, @user_id, @version, @profession, @name, @surname, @age, @birth_date, @hide_age, @email, @city, @salary, @salary_max, @salary_period, @salary_net, @negotiable, @currency, @phone, @education, @experience, @languages, @links, @projects, @certificates, @photo, @pdf_theme, @description, @created_at, @updated_at, @expires_at, @status)`
+	if _, err := tx.Exec(ctx, query1, args1); err != nil {
+		log.Println("Tx to insert (add cv): ", err)
+		return errors.New("bad response from database")
+	}
+
+	if err := insertSkills(ctx, tx, cv.ID, softSkill, cv.SoftSkills); err != nil {
+		log.Println("Tx to insert soft skills (add cv): ", err)
+		return errors.New("bad response from database")
+	}
+	if err := insertSkills(ctx, tx, cv.ID, hardSkill, cv.HardSkills); err != nil {
+		log.Println("Tx to insert hard skills (add cv): ", err)
+		return errors.New("bad response from database")
+	}
+
+	if err := insertRevision(ctx, tx, cv); err != nil {
+		log.Println("Tx to insert revision (add cv): ", err)
+		return errors.New("bad response from database")
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		log.Println("failed to commit tx (add cv): ", err)
+		return errors.New("bad response from database")
+	}
+
+	rp.cacheCV(cv)
+	rp.dropUserIndex(cv.OwnerID)
+
+	log.Println("CV successfully added")
+	return nil
+}
+
+// UpdateCV rewrites CV if its stored version still equals cv.Version
+func (rp *Postgres) UpdateCV(c context.Context, cv *ent.CV) error {
+	ctx, cancel := context.WithTimeout(c, time.Second*15)
+	defer cancel()
+
+	pool := rp.db.GetPool()
+
+	tx, errTx := pool.BeginTx(ctx, pgx.TxOptions{IsoLevel: pgx.ReadCommitted})
+	if errTx != nil {
+		log.Println("Beg Tx (update cv): ", errTx)
+		return errors.New("bad response from database")
+	}
+
+	defer func() {
+		errRb := tx.Rollback(ctx)
+		if errRb != nil && !errors.Is(errRb, pgx.ErrTxClosed) {
+			log.Println("Rollback Tx (update cv): ", errRb)
+		}
+	}()
+
+	args1 := pgx.NamedArgs{
+		"id":            cv.ID,
+		"user_id":       cv.OwnerID,
+		"version":       cv.Version,
+		"profession":    cv.Profession,
+		"name":          cv.Name,
+		"surname":       cv.Surname,
+		"age":           cv.Age,
+		"birth_date":    cv.BirthDate,
+		"hide_age":      cv.HideAge,
+		"email":         cv.EmailCV,
+		"city":          cv.LivingCity,
+		"salary":        cv.Salary,
+		"salary_max":    cv.SalaryMax,
+		"salary_period": cv.SalaryPeriod,
+		"salary_net":    cv.SalaryNet,
+		"negotiable":    cv.Negotiable,
+		"currency":      cv.Currency,
+		"phone":         cv.PhoneNumber,
+		"education":     jsonList(cv.Education),
+		"experience":    jsonList(cv.Experience),
+		"languages":     jsonList(cv.Languages),
+		"links":         jsonList(cv.Links),
+		"projects":      jsonList(cv.Projects),
+		"certificates":  jsonList(cv.Certificates),
+		"photo":         cv.Photo,
+		"description":   cv.Description,
+		"updated_at":    time.Now().UTC(),
+		"archived":      ent.StatusArchived,
+	}
+
+	query1 := `UPDATE cvs SET
+			profession = @profession, name = @name, surname = @surname, age = @age, birth_date = @birth_date,
+			hide_age = @hide_age, email = @email, city = @city, salary = @salary, salary_max = @salary_max,
+			salary_period = @salary_period, salary_net = @salary_net, negotiable = @negotiable, currency = @currency,
+			phone = @phone, education = @education, experience = @experience, languages = @languages, links = @links,
+			projects = @projects, certificates = @certificates, photo = @photo, description = @description,
+			updated_at = @updated_at, version = version + 1
+		WHERE id = @id AND user_id = @user_id AND version = @version AND status <> @archived AND deleted_at IS NULL
+		RETURNING version, created_at, expires_at, status, pdf_theme`
+	if err := tx.QueryRow(ctx, query1, args1).Scan(&cv.Version, &cv.CreatedAt, &cv.ExpiresAt, &cv.Status, &cv.PDFTheme); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return staleOrMissing(ctx, tx, cv.OwnerID, cv.ID)
+		}
+		log.Println("Tx to update (update cv): ", err)
+		return errors.New("bad response from database")
+	}
+
+	args2 := pgx.NamedArgs{"cv_id": cv.ID}
+	query2 := "DELETE FROM cv_skills WHERE cv_id = @cv_id"
+	if _, err := tx.Exec(ctx, query2, args2); err != nil {
+		log.Println("Tx to delete skills (update cv): ", err)
+		return errors.New("bad response from database")
+	}
+
+	if err := insertSkills(ctx, tx, cv.ID, softSkill, cv.SoftSkills); err != nil {
+		log.Println("Tx to insert soft skills (update cv): ", err)
+		return errors.New("bad response from database")
+	}
+	if err := insertSkills(ctx, tx, cv.ID, hardSkill, cv.HardSkills); err != nil {
+		log.Println("Tx to insert hard skills (update cv): ", err)
+		return errors.New("bad response from database")
+	}
+
+	if err := insertRevision(ctx, tx, cv); err != nil {
+		log.Println("Tx to insert revision (update cv): ", err)
+		return errors.New("bad response from database")
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		log.Println("failed to commit tx (update cv): ", err)
+		return errors.New("bad response from database")
+	}
+
+	rp.cacheCV(cv)
+
+	log.Println("CV successfully updated")
+	return nil
+}
+
+func staleOrMissing(ctx context.Context, tx pgx.Tx, id, cvID string) error {
+	var status string
+	args := pgx.NamedArgs{"id": cvID, "user_id": id}
+	query := "SELECT status FROM cvs WHERE id = @id AND user_id = @user_id AND deleted_at IS NULL"
+	if err := tx.QueryRow(ctx, query, args).Scan(&status); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return ent.ErrCVNotFound
+		}
+		log.Println("Tx to select (update cv): ", err)
+		return errors.New("bad response from database")
+	}
+	if status == ent.StatusArchived {
+		return ent.ErrArchivedCV
+	}
+	return ent.ErrStaleCV
+}
+
+// insertRevision saves cv as its newest revision, the photo goes to cv_photos
+func insertRevision(ctx context.Context, tx pgx.Tx, cv *ent.CV) error {
+	rev := newRevisionData(cv)
+	if rev.PhotoHash != "" {
+		args := pgx.NamedArgs{"cv_id": cv.ID, "hash": rev.PhotoHash, "data": cv.Photo}
+		query := "INSERT INTO cv_photos (cv_id, hash, data) VALUES (@cv_id, @hash, @data) ON CONFLICT DO NOTHING"
+		if _, err := tx.Exec(ctx, query, args); err != nil {
+			return err
+		}
+	}
+
+	data, err := json.Marshal(rev)
+	if err != nil {
+		return err
+	}
+
+	args := pgx.NamedArgs{
+		"cv_id":      cv.ID,
+		"version":    cv.Version,
+		"data":       data,
+		"created_at": time.Now().UTC(),
+	}
+	query := "INSERT INTO cv_revisions (cv_id, version, data, created_at) VALUES (@cv_id, @version, @data, @created_at)"
+	_, err = tx.Exec(ctx, query, args)
+	return err
+}
+
+func insertSkills(ctx context.Context, tx pgx.Tx, cvID, kind string, skills []string) error {
+	query := "INSERT INTO cv_skills (cv_id, kind, position, skill) VALUES (@cv_id, @kind, @position, @skill)"
+	for i, skill := range skills {
+		args := pgx.NamedArgs{
+			"cv_id":    cvID,
+			"kind":     kind,
+			"position": i,
+			"skill":    skill,
+		}
+		if _, err := tx.Exec(ctx, query, args); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+const cvColumns = `id, user_id, profession, name, surname, age, birth_date, hide_age, email, city,
+	salary, salary_max, salary_period, salary_net, negotiable, currency, phone, education, experience,
+	languages, links, projects, certificates, photo, pdf_theme, description, version, created_at, expires_at, status,
+	deleted_at`
+
+// jsonList encodes list for JSONB column, nil becomes empty array instead of null
+func jsonList[T any](list []T) []byte {
+	if list == nil {
+		list = []T{}
+	}
+	data, err := json.Marshal(list)
+	if err != nil {
+		log.Println(err)
+		return []byte("[]")
+	}
+	return data
+}
+
+func scanCV(row pgx.Row, cv *ent.CV) error {
+	return row.Scan(&cv.ID, &cv.OwnerID, &cv.Profession, &cv.Name, &cv.Surname, &cv.Age, &cv.BirthDate, &cv.HideAge,
+		&cv.EmailCV, &cv.LivingCity, &cv.Salary, &cv.SalaryMax, &cv.SalaryPeriod, &cv.SalaryNet, &cv.Negotiable,
+		&cv.Currency, &cv.PhoneNumber, &cv.Education, &cv.Experience, &cv.Languages, &cv.Links, &cv.Projects,
+		&cv.Certificates, &cv.Photo, &cv.PDFTheme, &cv.Description, &cv.Version, &cv.CreatedAt, &cv.ExpiresAt, &cv.Status, &cv.DeletedAt)
+}
+
+// loadSkills fills skills of all cvs with one query
+func loadSkills(ctx context.Context, pool *pgxpool.Pool, cvs []*ent.CV) error {
+	if len(cvs) == 0 {
+		return nil
+	}
+
+	byID := make(map[string]*ent.CV, len(cvs))
+	ids := make([]string, 0, len(cvs))
+	for _, cv := range cvs {
+		byID[cv.ID] = cv
+		ids = append(ids, cv.ID)
+	}
+
+	args := pgx.NamedArgs{"ids": ids}
+	query := "SELECT cv_id, kind, skill FROM cv_skills WHERE cv_id = ANY(@ids) ORDER BY cv_id, kind, position"
+	rows, err := pool.Query(ctx, query, args)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var cvID, kind, skill string
+		if err := rows.Scan(&cvID, &kind, &skill); err != nil {
+			return err
+		}
+		cv := byID[cvID]
+		switch kind {
+		case softSkill:
+			cv.SoftSkills = append(cv.SoftSkills, skill)
+		case hardSkill:
+			cv.HardSkills = append(cv.HardSkills, skill)
+		}
+	}
+
+	return rows.Err()
+}
+
+// GetUserCVs returns all CVs of user, first from redis index of user's CVs,
+// then with two batched queries to postgres
+func (rp *Postgres) GetUserCVs(c context.Context, id string) ([]ent.CV, error) {
+	if cvs, ok := rp.cachedUserCVs(id); ok {
+		return cvs, nil
+	}
+
+	ctx, cancel := context.WithTimeout(c, time.Second*15)
+	defer cancel()
+
+	pool := rp.db.GetPool()
+
+	args := pgx.NamedArgs{"user_id": id, "archived": ent.StatusArchived}
+	query := "SELECT " + cvColumns + " FROM cvs WHERE user_id = @user_id AND status <> @archived AND deleted_at IS NULL ORDER BY created_at"
+	rows, err := pool.Query(ctx, query, args)
+	if err != nil {
+		log.Println("bad resp (user cvs): ", err)
+		return nil, errors.New("bad response from database")
+	}
+
+	cvs, err := pgx.CollectRows(rows, func(row pgx.CollectableRow) (*ent.CV, error) {
+		cv := &ent.CV{}
+		err := scanCV(row, cv)
+		return cv, err
+	})
+	if err != nil {
+		log.Println("bad rows (user cvs): ", err)
+		return nil, errors.New("bad response from database")
+	}
+
+	if err := loadSkills(ctx, pool, cvs); err != nil {
+		log.Println("bad resp (skills): ", err)
+		return nil, errors.New("bad response from database")
+	}
+
+	rp.cacheUserCVs(id, cvs)
+
+	result := make([]ent.CV, 0, len(cvs))
+	for _, cv := range cvs {
+		result = append(result, *cv)
+	}
+	return result, nil
+}
+
+// GetAllUserCVs returns every CV of user including archived and deleted ones, bypassing cache
+func (rp *Postgres) GetAllUserCVs(c context.Context, id string) ([]ent.CV, error) {
+	ctx, cancel := context.WithTimeout(c, time.Second*15)
+	defer cancel()
+
+	pool := rp.db.GetPool()
+
+	args := pgx.NamedArgs{"user_id": id}
+	query := "SELECT " + cvColumns + " FROM cvs WHERE user_id = @user_id ORDER BY created_at"
+	rows, err := pool.Query(ctx, query, args)
+	if err != nil {
+		log.Println("bad resp (all user cvs): ", err)
+		return nil, errors.New("bad response from database")
+	}
+
+	cvs, err := pgx.CollectRows(rows, func(row pgx.CollectableRow) (*ent.CV, error) {
+		cv := &ent.CV{}
+		err := scanCV(row, cv)
+		return cv, err
+	})
+	if err != nil {
+		log.Println("bad rows (all user cvs): ", err)
+		return nil, errors.New("bad response from database")
+	}
+
+	if err := loadSkills(ctx, pool, cvs); err != nil {
+		log.Println("bad resp (skills): ", err)
+		return nil, errors.New("bad response from database")
+	}
+
+	result := make([]ent.CV, 0, len(cvs))
+	for _, cv := range cvs {
+		result = append(result, *cv)
+	}
+	return result, nil
+}
+
+func (rp *Postgres) GetDataCV(c context.Context, id string, cvID string) (*ent.CV, error) {
+	if cv, ok := rp.cachedCV(cvID); ok && cv.OwnerID == id {
+		return cv, nil
+	}
+
+	ctx, cancel := context.WithTimeout(c, time.Second*15)
+	defer cancel()
+
+	pool := rp.db.GetPool()
+
+	cv := &ent.CV{}
+
+	args := pgx.NamedArgs{"id": cvID, "user_id": id}
+	query := "SELECT " + cvColumns + " FROM cvs WHERE id = @id AND user_id = @user_id AND deleted_at IS NULL"
+	if err := scanCV(pool.QueryRow(ctx, query, args), cv); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ent.ErrCVNotFound
+		}
+		log.Println("bad resp (cv): ", err)
+		return nil, errors.New("bad response from database")
+	}
+
+	if err := loadSkills(ctx, pool, []*ent.CV{cv}); err != nil {
+		log.Println("bad resp (skills): ", err)
+		return nil, errors.New("bad response from database")
+	}
+
+	rp.cacheCV(cv)
+
+	return cv, nil
+}
+
+// ExtendCV moves expiry of CV to until and makes it active again
+func (rp *Postgres) ExtendCV(c context.Context, id, cvID string, until time.Time) error {
+	ctx, cancel := context.WithTimeout(c, time.Second*15)
+	defer cancel()
+
+	pool := rp.db.GetPool()
+
+	args := pgx.NamedArgs{
+		"id":         cvID,
+		"user_id":    id,
+		"expires_at": until,
+		"active":     ent.StatusActive,
+	}
+	query := "UPDATE cvs SET expires_at = @expires_at, status = @active WHERE id = @id AND user_id = @user_id AND deleted_at IS NULL"
+	tag, err := pool.Exec(ctx, query, args)
+	if err != nil {
+		log.Println("bad resp (extend cv): ", err)
+		return errors.New("bad response from database")
+	}
+	if tag.RowsAffected() == 0 {
+		return ent.ErrCVNotFound
+	}
+
+	rp.uncache(cvKey(cvID), userCVsKey(id))
+	return nil
+}
+
+// SetPDFTheme remembers theme of CV's PDF without making a new revision
+func (rp *Postgres) SetPDFTheme(c context.Context, id, cvID, theme string) error {
+	ctx, cancel := context.WithTimeout(c, time.Second*15)
+	defer cancel()
+
+	pool := rp.db.GetPool()
+
+	args := pgx.NamedArgs{
+		"id":        cvID,
+		"user_id":   id,
+		"pdf_theme": theme,
+	}
+	query := "UPDATE cvs SET pdf_theme = @pdf_theme WHERE id = @id AND user_id = @user_id AND deleted_at IS NULL"
+	tag, err := pool.Exec(ctx, query, args)
+	if err != nil {
+		log.Println("bad resp (set pdf theme): ", err)
+		return errors.New("bad response from database")
+	}
+	if tag.RowsAffected() == 0 {
+		return ent.ErrCVNotFound
+	}
+
+	rp.uncache(cvKey(cvID), userCVsKey(id))
+	return nil
+}
+
+// SweepCVs marks CVs expiring before notice and archives (or deletes) CVs expired before now
+func (rp *Postgres) SweepCVs(c context.Context, now, notice time.Time, archive bool) (int, int, error) {
+	ctx, cancel := context.WithTimeout(c, time.Second*30)
+	defer cancel()
+
+	pool := rp.db.GetPool()
+
+	tx, errTx := pool.BeginTx(ctx, pgx.TxOptions{IsoLevel: pgx.ReadCommitted})
+	if errTx != nil {
+		log.Println("Beg Tx (sweep cvs): ", errTx)
+		return 0, 0, errors.New("bad response from database")
+	}
+
+	defer func() {
+		errRb := tx.Rollback(ctx)
+		if errRb != nil && !errors.Is(errRb, pgx.ErrTxClosed) {
+			log.Println("Rollback Tx (sweep cvs): ", errRb)
+		}
+	}()
+
+	args := pgx.NamedArgs{
+		"now":      now,
+		"notice":   notice,
+		"active":   ent.StatusActive,
+		"expiring": ent.StatusExpiring,
+		"archived": ent.StatusArchived,
+	}
+
+	query1 := `UPDATE cvs SET status = @expiring
+		WHERE status = @active AND expires_at <= @notice AND expires_at > @now AND deleted_at IS NULL
+		RETURNING id, user_id`
+	expiring, err := collectOwnedIDs(ctx, tx, query1, args)
+	if err != nil {
+		log.Println("Tx to mark expiring (sweep cvs): ", err)
+		return 0, 0, errors.New("bad response from database")
+	}
+
+	query2 := `UPDATE cvs SET status = @archived
+		WHERE status <> @archived AND expires_at <= @now AND deleted_at IS NULL
+		RETURNING id, user_id`
+	if !archive {
+		query2 = "DELETE FROM cvs WHERE expires_at <= @now AND deleted_at IS NULL RETURNING id, user_id"
+	}
+	expired, err := collectOwnedIDs(ctx, tx, query2, args)
+	if err != nil {
+		log.Println("Tx to expire (sweep cvs): ", err)
+		return 0, 0, errors.New("bad response from database")
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		log.Println("failed to commit tx (sweep cvs): ", err)
+		return 0, 0, errors.New("bad response from database")
+	}
+
+	keys := []string{}
+	for cvID, id := range expiring {
+		keys = append(keys, cvKey(cvID), userCVsKey(id))
+	}
+	for cvID, id := range expired {
+		keys = append(keys, cvKey(cvID), userCVsKey(id))
+	}
+	rp.uncache(keys...)
+
+	return len(expiring), len(expired), nil
+}
+
+// collectOwnedIDs runs query returning (id, user_id) rows and maps CV ID to its owner
+func collectOwnedIDs(ctx context.Context, tx pgx.Tx, query string, args pgx.NamedArgs) (map[string]string, error) {
+	rows, err := tx.Query(ctx, query, args)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	owners := make(map[string]string)
+	for rows.Next() {
+		var cvID, id string
+		if err := rows.Scan(&cvID, &id); err != nil {
+			return nil, err
+		}
+		owners[cvID] = id
+	}
+
+	return owners, rows.Err()
+}
+
+func (rp *Postgres) ListRevisions(c context.Context, id, cvID string) ([]ent.Revision, error) {
+	ctx, cancel := context.WithTimeout(c, time.Second*15)
+	defer cancel()
+
+	pool := rp.db.GetPool()
+
+	args := pgx.NamedArgs{"id": cvID, "user_id": id}
+	query := `SELECT r.version, r.created_at FROM cv_revisions r
+		JOIN cvs ON cvs.id = r.cv_id
+		WHERE cvs.id = @id AND cvs.user_id = @user_id AND cvs.deleted_at IS NULL
+		ORDER BY r.version DESC`
+	rows, err := pool.Query(ctx, query, args)
+	if err != nil {
+		log.Println("bad resp (revisions): ", err)
+		return nil, errors.New("bad response from database")
+	}
+
+	revisions, err := pgx.CollectRows(rows, func(row pgx.CollectableRow) (ent.Revision, error) {
+		rev := ent.Revision{}
+		err := row.Scan(&rev.Version, &rev.CreatedAt)
+		return rev, err
+	})
+	if err != nil {
+		log.Println("bad rows (revisions): ", err)
+		return nil, errors.New("bad response from database")
+	}
+
+	return revisions, nil
+}
+
+func (rp *Postgres) GetRevision(c context.Context, id, cvID string, version int) (*ent.Revision, error) {
+	ctx, cancel := context.WithTimeout(c, time.Second*15)
+	defer cancel()
+
+	pool := rp.db.GetPool()
+
+	var data, photo []byte
+	rev := &ent.Revision{Version: version, CV: &ent.CV{}}
+
+	args := pgx.NamedArgs{"id": cvID, "user_id": id, "version": version}
+	query := `SELECT r.data, r.created_at, p.data FROM cv_revisions r
+		JOIN cvs ON cvs.id = r.cv_id
+		LEFT JOIN cv_photos p ON p.cv_id = r.cv_id AND p.hash = r.data->>'photo_sha256'
+		WHERE cvs.id = @id AND cvs.user_id = @user_id AND cvs.deleted_at IS NULL AND r.version = @version`
+	if err := pool.QueryRow(ctx, query, args).Scan(&data, &rev.CreatedAt, &photo); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ent.ErrNoRevision
+		}
+		log.Println("bad resp (revision): ", err)
+		return nil, errors.New("bad response from database")
+	}
+
+	if err := json.Unmarshal(data, &revisionData{CV: rev.CV}); err != nil {
+		log.Println("bad revision data: ", err)
+		return nil, errors.New("bad response from database")
+	}
+	if photo != nil {
+		rev.CV.Photo = photo
+	}
+
+	return rev, nil
+}
+
+// DeleteCV moves CV to trash, see PurgeCV and PurgeTrash for removing it for good
+func (rp *Postgres) DeleteCV(c context.Context, id, cvID string) error {
+	ctx, cancel := context.WithTimeout(c, time.Second*15)
+	defer cancel()
+
+	pool := rp.db.GetPool()
+
+	args := pgx.NamedArgs{"id": cvID, "user_id": id, "deleted_at": time.Now().UTC()}
+	query := "UPDATE cvs SET deleted_at = @deleted_at WHERE id = @id AND user_id = @user_id AND deleted_at IS NULL"
+	tag, err := pool.Exec(ctx, query, args)
+	if err != nil {
+		log.Println("bad resp (delete cv): ", err)
+		return errors.New("bad response from database")
+	}
+	if tag.RowsAffected() == 0 {
+		return ent.ErrCVNotFound
+	}
+
+	rp.uncache(cvKey(cvID), userCVsKey(id))
+	return nil
+}
+
+// ListTrash returns deleted CVs of user, the most recently deleted first
+func (rp *Postgres) ListTrash(c context.Context, id string) ([]ent.CV, error) {
+	ctx, cancel := context.WithTimeout(c, time.Second*15)
+	defer cancel()
+
+	pool := rp.db.GetPool()
+
+	args := pgx.NamedArgs{"user_id": id}
+	query := "SELECT " + cvColumns + " FROM cvs WHERE user_id = @user_id AND deleted_at IS NOT NULL ORDER BY deleted_at DESC"
+	rows, err := pool.Query(ctx, query, args)
+	if err != nil {
+		log.Println("bad resp (trash): ", err)
+		return nil, errors.New("bad response from database")
+	}
+
+	cvs, err := pgx.CollectRows(rows, func(row pgx.CollectableRow) (ent.CV, error) {
+		cv := ent.CV{}
+		err := scanCV(row, &cv)
+		return cv, err
+	})
+	if err != nil {
+		log.Println("bad rows (trash): ", err)
+		return nil, errors.New("bad response from database")
+	}
+
+	return cvs, nil
+}
+
+// RestoreCV takes CV back from trash
+func (rp *Postgres) RestoreCV(c context.Context, id, cvID string) error {
+	ctx, cancel := context.WithTimeout(c, time.Second*15)
+	defer cancel()
+
+	pool := rp.db.GetPool()
+
+	args := pgx.NamedArgs{"id": cvID, "user_id": id}
+	query := "UPDATE cvs SET deleted_at = NULL WHERE id = @id AND user_id = @user_id AND deleted_at IS NOT NULL"
+	tag, err := pool.Exec(ctx, query, args)
+	if err != nil {
+		log.Println("bad resp (restore cv): ", err)
+		return errors.New("bad response from database")
+	}
+	if tag.RowsAffected() == 0 {
+		return ent.ErrCVNotFound
+	}
+
+	rp.dropUserIndex(id)
+	return nil
+}
+
+// PurgeCV removes CV from trash for good
+func (rp *Postgres) PurgeCV(c context.Context, id, cvID string) error {
+	ctx, cancel := context.WithTimeout(c, time.Second*15)
+	defer cancel()
+
+	pool := rp.db.GetPool()
+
+	args := pgx.NamedArgs{"id": cvID, "user_id": id}
+	query := "DELETE FROM cvs WHERE id = @id AND user_id = @user_id AND deleted_at IS NOT NULL"
+	tag, err := pool.Exec(ctx, query, args)
+	if err != nil {
+		log.Println("bad resp (purge cv): ", err)
+		return errors.New("bad response from database")
+	}
+	if tag.RowsAffected() == 0 {
+		return ent.ErrCVNotFound
+	}
+
+	return nil
+}
+
+// PurgeTrash removes CVs deleted before the time
+func (rp *Postgres) PurgeTrash(c context.Context, before time.Time) (int, error) {
+	ctx, cancel := context.WithTimeout(c, time.Second*30)
+	defer cancel()
+
+	pool := rp.db.GetPool()
+
+	args := pgx.NamedArgs{"before": before}
+	query := "DELETE FROM cvs WHERE deleted_at <= @before"
+	tag, err := pool.Exec(ctx, query, args)
+	if err != nil {
+		log.Println("bad resp (purge trash): ", err)
+		return 0, errors.New("bad response from database")
+	}
+
+	return int(tag.RowsAffected()), nil
+}
@@ -0,0 +1,131 @@
+package repository
+
+import (
+	"encoding/json"
+	"log"
+	"sort"
+
+	ent "github.com/Vladroon22/CVmaker/internal/entity"
+	"github.com/Vladroon22/CVmaker/internal/utils"
+)
+
+func cvKey(cvID string) string {
+	return "cv:" + cvID
+}
+
+// userCVsKey is redis set with IDs of all user's CVs
+func userCVsKey(id string) string {
+	return "cvs:user:" + id
+}
+
+// cacheCV puts CV in redis; failure is only logged since postgres keeps the data
+func (rp *Postgres) cacheCV(cv *ent.CV) {
+	if rp.red == nil {
+		return
+	}
+
+	jsonData, err := json.Marshal(cv)
+	if err != nil {
+		log.Println(err)
+		return
+	}
+	if err := rp.red.SetData(cvKey(cv.ID), string(jsonData), utils.TTLofCVCache); err != nil {
+		log.Println("redis error: ", err)
+	}
+}
+
+func (rp *Postgres) cachedCV(cvID string) (*ent.CV, bool) {
+	if rp.red == nil {
+		return nil, false
+	}
+
+	data, err := rp.red.GetData(cvKey(cvID))
+	if err != nil || data == "" {
+		return nil, false
+	}
+
+	cv := &ent.CV{}
+	if err := json.Unmarshal([]byte(data), cv); err != nil {
+		log.Println(err)
+		return nil, false
+	}
+	return cv, true
+}
+
+// cacheUserCVs puts CVs and the index of user's CVs in redis
+func (rp *Postgres) cacheUserCVs(id string, cvs []*ent.CV) {
+	if rp.red == nil {
+		return
+	}
+
+	data := make(map[string]string, len(cvs))
+	ids := make([]string, 0, len(cvs))
+	for _, cv := range cvs {
+		jsonData, err := json.Marshal(cv)
+		if err != nil {
+			log.Println(err)
+			return
+		}
+		data[cvKey(cv.ID)] = string(jsonData)
+		ids = append(ids, cv.ID)
+	}
+
+	if err := rp.red.SetMany(data, utils.TTLofCVCache); err != nil {
+		log.Println("redis error: ", err)
+		return
+	}
+	if err := rp.red.ReplaceSet(userCVsKey(id), utils.TTLofCVCache, ids...); err != nil {
+		log.Println("redis error: ", err)
+	}
+}
+
+// cachedUserCVs reads the whole list from redis or reports miss if any CV is absent
+func (rp *Postgres) cachedUserCVs(id string) ([]ent.CV, bool) {
+	if rp.red == nil {
+		return nil, false
+	}
+
+	ids, err := rp.red.SetMembers(userCVsKey(id))
+	if err != nil || len(ids) == 0 {
+		return nil, false
+	}
+
+	keys := make([]string, len(ids))
+	for i, cvID := range ids {
+		keys[i] = cvKey(cvID)
+	}
+
+	data, err := rp.red.GetMany(keys...)
+	if err != nil {
+		log.Println("redis error: ", err)
+		return nil, false
+	}
+
+	cvs := make([]ent.CV, 0, len(data))
+	for _, d := range data {
+		if d == "" {
+			return nil, false
+		}
+		cv := ent.CV{}
+		if err := json.Unmarshal([]byte(d), &cv); err != nil || cv.OwnerID != id {
+			return nil, false
+		}
+		cvs = append(cvs, cv)
+	}
+
+	sort.Slice(cvs, func(i, j int) bool { return cvs[i].CreatedAt.Before(cvs[j].CreatedAt) })
+	return cvs, true
+}
+
+func (rp *Postgres) dropUserIndex(id string) {
+	rp.uncache(userCVsKey(id))
+}
+
+func (rp *Postgres) uncache(keys ...string) {
+	if rp.red == nil || len(keys) == 0 {
+		return
+	}
+	if err := rp.red.Delete(keys...); err != nil {
+		log.Println("redis error: ", err)
+	}
+}
@@ -0,0 +1,820 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/Vladroon22/CVmaker/internal/database"
+	ent "github.com/Vladroon22/CVmaker/internal/entity"
+	"github.com/Vladroon22/CVmaker/internal/utils"
+	"github.com/go-redis/redis"
+	"github.com/google/uuid"
+)
+
+// Redis keeps all data in redis without TTL, so redis must be configured with persistence
+type Redis struct {
+	rd *redis.Client
+}
+
+func NewRedis(r *database.Redis) *Redis {
+	return &Redis{rd: r.GetClient()}
+}
+
+// keys of the storage are prefixed with "store:" to not mix with cache of postgres storage
+func userKey(id string) string {
+	return "store:user:" + id
+}
+
+func emailKey(email string) string {
+	return "store:email:" + email
+}
+
+func sessionsKey(id string) string {
+	return "store:sessions:" + id
+}
+
+func storedCVKey(cvID string) string {
+	return "store:cv:" + cvID
+}
+
+func storedUserCVsKey(id string) string {
+	return "store:cvs:user:" + id
+}
+
+func revisionsKey(cvID string) string {
+	return "store:cv:" + cvID + ":revisions"
+}
+
+// photosKey is a hash of photos of CV's revisions by their SHA-256
+func photosKey(cvID string) string {
+	return "store:cv:" + cvID + ":photos"
+}
+
+// tombstoneKey keeps digest of the email of deleted user
+func tombstoneKey(id string) string {
+	return "store:tombstone:" + id
+}
+
+// expiryKey and trashKey are sorted sets of CV IDs scored by unix time of expiry and deletion
+const (
+	expiryKey = "store:cvs:expiry"
+	trashKey  = "store:cvs:trash"
+)
+
+func (rs *Redis) Login(c context.Context, pass, email string) (string, error) {
+	id, err := rs.rd.Get(emailKey(email)).Result()
+	if err != nil {
+		if errors.Is(err, redis.Nil) {
+			log.Println(errNoUser)
+			return "", errNoUser
+		}
+		log.Println("redis error: ", err)
+		return "", errors.New("bad response from database")
+	}
+
+	data, err := rs.rd.Get(userKey(id)).Result()
+	if err != nil {
+		log.Println("redis error: ", err)
+		return "", errors.New("bad response from database")
+	}
+
+	user := storedUser{}
+	if err := json.Unmarshal([]byte(data), &user); err != nil {
+		log.Println(err)
+		return "", errors.New("bad response from database")
+	}
+
+	if err := utils.CheckPassAndHash(user.Hash, pass); err != nil {
+		log.Println(err)
+		return "", errWrongPass
+	}
+
+	return id, nil
+}
+
+func (rs *Redis) SaveSession(c context.Context, id string, device string) error {
+	jsonData, err := json.Marshal(storedSession{Device: device, CreatedAt: time.Now().UTC()})
+	if err != nil {
+		log.Println(err)
+		return err
+	}
+
+	key := sessionsKey(id)
+	err = rs.rd.Watch(func(tx *redis.Tx) error {
+		cnt, err := tx.LLen(key).Result()
+		if err != nil {
+			return err
+		}
+		_, err = tx.Pipelined(func(p redis.Pipeliner) error {
+			if cnt >= maxSessions {
+				p.Del(key)
+			}
+			p.RPush(key, string(jsonData))
+			return nil
+		})
+		return err
+	}, key)
+	if err != nil {
+		log.Println("redis error (session): ", err)
+		return errors.New("bad response from database")
+	}
+
+	log.Println("User successfully log in")
+	return nil
+}
+
+func (rs *Redis) CreateUser(c context.Context, user *ent.UserInput) error {
+	encPass, err := utils.Hashing(user.Password)
+	if err != nil {
+		log.Println(err)
+		return err
+	}
+
+	id := uuid.NewString()
+	ok, err := rs.rd.SetNX(emailKey(user.Email), id, 0).Result()
+	if err != nil {
+		log.Println("redis error (user): ", err)
+		return errors.New("bad response from database")
+	}
+	if !ok {
+		log.Println(errUserExisted)
+		return errUserExisted
+	}
+
+	jsonData, err := json.Marshal(storedUser{
+		ID:        id,
+		Name:      user.Name,
+		Email:     user.Email,
+		Hash:      string(encPass),
+		CreatedAt: time.Now().UTC(),
+	})
+	if err != nil {
+		log.Println(err)
+		return err
+	}
+
+	if err := rs.rd.Set(userKey(id), string(jsonData), 0).Err(); err != nil {
+		log.Println("redis error (user): ", err)
+		rs.rd.Del(emailKey(user.Email))
+		return errors.New("bad response from database")
+	}
+
+	log.Println("User successfully added")
+	return nil
+}
+
+func (rs *Redis) GetUser(c context.Context, id string) (*ent.User, error) {
+	data, err := rs.rd.Get(userKey(id)).Result()
+	if err != nil {
+		if errors.Is(err, redis.Nil) {
+			return nil, errNoUser
+		}
+		log.Println("redis error (user): ", err)
+		return nil, errors.New("bad response from database")
+	}
+
+	user := storedUser{}
+	if err := json.Unmarshal([]byte(data), &user); err != nil {
+		log.Println(err)
+		return nil, errors.New("bad response from database")
+	}
+
+	return &ent.User{ID: user.ID, Name: user.Name, Email: user.Email, CreatedAt: user.CreatedAt}, nil
+}
+
+func (rs *Redis) DeleteUser(c context.Context, id string) error {
+	data, err := rs.rd.Get(userKey(id)).Result()
+	if err != nil {
+		if errors.Is(err, redis.Nil) {
+			return errNoUser
+		}
+		log.Println("redis error (delete user): ", err)
+		return errors.New("bad response from database")
+	}
+
+	user := storedUser{}
+	if err := json.Unmarshal([]byte(data), &user); err != nil {
+		log.Println(err)
+		return errors.New("bad response from database")
+	}
+
+	// watching index of user's CVs, so CV added meanwhile fails the deletion instead of being left behind
+	err = rs.rd.Watch(func(tx *redis.Tx) error {
+		cvs, err := rs.allUserCVs(id)
+		if err != nil {
+			return err
+		}
+
+		_, err = tx.Pipelined(func(p redis.Pipeliner) error {
+			for i := range cvs {
+				removeCV(p, &cvs[i])
+			}
+			p.Del(storedUserCVsKey(id), sessionsKey(id), emailKey(user.Email), userKey(id))
+			p.Set(tombstoneKey(id), emailDigest(user.Email), 0)
+			return nil
+		})
+		return err
+	}, storedUserCVsKey(id))
+	if err != nil {
+		log.Println("redis error (delete user): ", err)
+		return errors.New("bad response from database")
+	}
+
+	log.Println("User successfully deleted")
+	return nil
+}
+
+func (rs *Redis) ListSessions(c context.Context, id string) ([]ent.Session, error) {
+	values, err := rs.rd.LRange(sessionsKey(id), 0, -1).Result()
+	if err != nil {
+		log.Println("redis error (sessions): ", err)
+		return nil, errors.New("bad response from database")
+	}
+
+	sessions := make([]ent.Session, 0, len(values))
+	for _, v := range values {
+		s := storedSession{}
+		if err := json.Unmarshal([]byte(v), &s); err != nil {
+			log.Println(err)
+			return nil, errors.New("bad response from database")
+		}
+		sessions = append(sessions, ent.Session{Device: s.Device, CreatedAt: s.CreatedAt})
+	}
+	return sessions, nil
+}
+
+func (rs *Redis) AddNewCV(c context.Context, cv *ent.CV) error {
+	cv.ID = uuid.NewString()
+	cv.Version = 1
+	cv.CreatedAt = time.Now().UTC()
+
+	jsonCV, jsonRev, err := marshalWithRevision(cv, cv.CreatedAt)
+	if err != nil {
+		log.Println(err)
+		return err
+	}
+
+	_, err = rs.rd.TxPipelined(func(p redis.Pipeliner) error {
+		p.Set(storedCVKey(cv.ID), jsonCV, 0)
+		p.SAdd(storedUserCVsKey(cv.OwnerID), cv.ID)
+		pushRevision(p, cv, jsonRev)
+		p.ZAdd(expiryKey, redis.Z{Score: float64(cv.ExpiresAt.Unix()), Member: cv.ID})
+		return nil
+	})
+	if err != nil {
+		log.Println("redis error (cv): ", err)
+		return errors.New("bad response from database")
+	}
+
+	log.Println("CV successfully added")
+	return nil
+}
+
+// UpdateCV saves CV if nobody changed it since it was read, see ent.ErrStaleCV
+func (rs *Redis) UpdateCV(c context.Context, cv *ent.CV) error {
+	err := rs.watchCV(cv.ID, func(tx *redis.Tx, old *ent.CV) error {
+		if old.OwnerID != cv.OwnerID || old.DeletedAt != nil {
+			return ent.ErrCVNotFound
+		}
+		if old.Status == ent.StatusArchived {
+			return ent.ErrArchivedCV
+		}
+		if old.Version != cv.Version {
+			return ent.ErrStaleCV
+		}
+
+		cv.Version = old.Version + 1
+		cv.CreatedAt = old.CreatedAt
+		cv.ExpiresAt = old.ExpiresAt
+		cv.Status = old.Status
+		cv.PDFTheme = old.PDFTheme
+
+		jsonCV, jsonRev, err := marshalWithRevision(cv, time.Now().UTC())
+		if err != nil {
+			return err
+		}
+
+		_, err = tx.Pipelined(func(p redis.Pipeliner) error {
+			p.Set(storedCVKey(cv.ID), jsonCV, 0)
+			pushRevision(p, cv, jsonRev)
+			return nil
+		})
+		return err
+	})
+
+	switch {
+	case err == nil:
+		log.Println("CV successfully updated")
+		return nil
+	case errors.Is(err, redis.TxFailedErr):
+		return ent.ErrStaleCV
+	case errors.Is(err, ent.ErrCVNotFound), errors.Is(err, ent.ErrArchivedCV), errors.Is(err, ent.ErrStaleCV):
+		return err
+	default:
+		log.Println("redis error (update cv): ", err)
+		return errors.New("bad response from database")
+	}
+}
+
+// SetPDFTheme remembers theme of CV's PDF without making a new revision
+func (rs *Redis) SetPDFTheme(c context.Context, id, cvID, theme string) error {
+	err := rs.watchCV(cvID, func(tx *redis.Tx, cv *ent.CV) error {
+		if cv.OwnerID != id || cv.DeletedAt != nil {
+			return ent.ErrCVNotFound
+		}
+
+		cv.PDFTheme = theme
+
+		jsonCV, err := json.Marshal(cv)
+		if err != nil {
+			return err
+		}
+
+		_, err = tx.Pipelined(func(p redis.Pipeliner) error {
+			p.Set(storedCVKey(cvID), string(jsonCV), 0)
+			return nil
+		})
+		return err
+	})
+
+	switch {
+	case err == nil:
+		return nil
+	case errors.Is(err, ent.ErrCVNotFound):
+		return err
+	default:
+		log.Println("redis error (set pdf theme): ", err)
+		return errors.New("bad response from database")
+	}
+}
+
+// watchCV loads CV under WATCH, so writes of fn through tx fail if CV was changed meanwhile
+func (rs *Redis) watchCV(cvID string, fn func(*redis.Tx, *ent.CV) error) error {
+	key := storedCVKey(cvID)
+	return rs.rd.Watch(func(tx *redis.Tx) error {
+		data, err := tx.Get(key).Result()
+		if err != nil {
+			if errors.Is(err, redis.Nil) {
+				return ent.ErrCVNotFound
+			}
+			return err
+		}
+
+		cv := &ent.CV{}
+		if err := json.Unmarshal([]byte(data), cv); err != nil {
+			return err
+		}
+		return fn(tx, cv)
+	}, key)
+}
+
+// storedRevision is ent.Revision as kept in redis, the photo is in photosKey
+type storedRevision struct {
+	Version   int          `json:"version"`
+	CreatedAt time.Time    `json:"created_at"`
+	CV        revisionData `json:"cv"`
+}
+
+func marshalWithRevision(cv *ent.CV, at time.Time) (string, string, error) {
+	jsonCV, err := json.Marshal(cv)
+	if err != nil {
+		return "", "", err
+	}
+	jsonRev, err := json.Marshal(storedRevision{Version: cv.Version, CreatedAt: at, CV: newRevisionData(cv)})
+	if err != nil {
+		return "", "", err
+	}
+	return string(jsonCV), string(jsonRev), nil
+}
+
+// pushRevision appends revision made by marshalWithRevision, photo of cv is stored once
+func pushRevision(p redis.Pipeliner, cv *ent.CV, jsonRev string) {
+	p.RPush(revisionsKey(cv.ID), jsonRev)
+	if len(cv.Photo) != 0 {
+		p.HSetNX(photosKey(cv.ID), newRevisionData(cv).PhotoHash, cv.Photo)
+	}
+}
+
+func (rs *Redis) GetUserCVs(c context.Context, id string) ([]ent.CV, error) {
+	all, err := rs.allUserCVs(id)
+	if err != nil {
+		return nil, err
+	}
+
+	cvs := make([]ent.CV, 0, len(all))
+	for _, cv := range all {
+		if cv.Status != ent.StatusArchived && cv.DeletedAt == nil {
+			cvs = append(cvs, cv)
+		}
+	}
+
+	sort.Slice(cvs, func(i, j int) bool { return cvs[i].CreatedAt.Before(cvs[j].CreatedAt) })
+	return cvs, nil
+}
+
+func (rs *Redis) GetAllUserCVs(c context.Context, id string) ([]ent.CV, error) {
+	cvs, err := rs.allUserCVs(id)
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Slice(cvs, func(i, j int) bool { return cvs[i].CreatedAt.Before(cvs[j].CreatedAt) })
+	return cvs, nil
+}
+
+// allUserCVs reads every CV of user including archived and deleted ones
+func (rs *Redis) allUserCVs(id string) ([]ent.CV, error) {
+	ids, err := rs.rd.SMembers(storedUserCVsKey(id)).Result()
+	if err != nil {
+		log.Println("redis error (user cvs): ", err)
+		return nil, errors.New("bad response from database")
+	}
+	if len(ids) == 0 {
+		return []ent.CV{}, nil
+	}
+
+	keys := make([]string, len(ids))
+	for i, cvID := range ids {
+		keys[i] = storedCVKey(cvID)
+	}
+
+	values, err := rs.rd.MGet(keys...).Result()
+	if err != nil {
+		log.Println("redis error (user cvs): ", err)
+		return nil, errors.New("bad response from database")
+	}
+
+	cvs := make([]ent.CV, 0, len(values))
+	for _, v := range values {
+		data, ok := v.(string)
+		if !ok {
+			continue
+		}
+		cv := ent.CV{}
+		if err := json.Unmarshal([]byte(data), &cv); err != nil {
+			log.Println(err)
+			return nil, errors.New("bad response from database")
+		}
+		cvs = append(cvs, cv)
+	}
+
+	return cvs, nil
+}
+
+func (rs *Redis) GetDataCV(c context.Context, id string, cvID string) (*ent.CV, error) {
+	data, err := rs.rd.Get(storedCVKey(cvID)).Result()
+	if err != nil {
+		if errors.Is(err, redis.Nil) {
+			return nil, ent.ErrCVNotFound
+		}
+		log.Println("redis error (cv): ", err)
+		return nil, errors.New("bad response from database")
+	}
+
+	cv := &ent.CV{}
+	if err := json.Unmarshal([]byte(data), cv); err != nil {
+		log.Println(err)
+		return nil, errors.New("bad response from database")
+	}
+	if cv.OwnerID != id || cv.DeletedAt != nil {
+		return nil, ent.ErrCVNotFound
+	}
+
+	return cv, nil
+}
+
+// ExtendCV moves expiry of CV to until and makes it active again
+func (rs *Redis) ExtendCV(c context.Context, id, cvID string, until time.Time) error {
+	err := rs.watchCV(cvID, func(tx *redis.Tx, cv *ent.CV) error {
+		if cv.OwnerID != id || cv.DeletedAt != nil {
+			return ent.ErrCVNotFound
+		}
+
+		cv.ExpiresAt = until
+		cv.Status = ent.StatusActive
+
+		jsonCV, err := json.Marshal(cv)
+		if err != nil {
+			return err
+		}
+
+		_, err = tx.Pipelined(func(p redis.Pipeliner) error {
+			p.Set(storedCVKey(cvID), string(jsonCV), 0)
+			p.ZAdd(expiryKey, redis.Z{Score: float64(until.Unix()), Member: cvID})
+			return nil
+		})
+		return err
+	})
+
+	switch {
+	case err == nil:
+		return nil
+	case errors.Is(err, ent.ErrCVNotFound):
+		return err
+	default:
+		log.Println("redis error (extend cv): ", err)
+		return errors.New("bad response from database")
+	}
+}
+
+// SweepCVs walks CVs expiring before notice; CVs changed during the sweep are left for the next one
+func (rs *Redis) SweepCVs(c context.Context, now, notice time.Time, archive bool) (int, int, error) {
+	ids, err := rs.rd.ZRangeByScore(expiryKey, redis.ZRangeBy{
+		Min: "-inf",
+		Max: strconv.FormatInt(notice.Unix(), 10),
+	}).Result()
+	if err != nil {
+		log.Println("redis error (sweep): ", err)
+		return 0, 0, errors.New("bad response from database")
+	}
+
+	expiring, expired := 0, 0
+	for _, cvID := range ids {
+		// status is the one set by the sweep, it's counted once the transaction is committed
+		status := ""
+		err := rs.watchCV(cvID, func(tx *redis.Tx, cv *ent.CV) error {
+			status = ""
+			if cv.DeletedAt != nil {
+				return nil
+			}
+			if cv.ExpiresAt.After(now) {
+				if cv.Status != ent.StatusActive {
+					return nil
+				}
+				cv.Status = ent.StatusExpiring
+			} else {
+				cv.Status = ent.StatusArchived
+			}
+
+			jsonCV, err := json.Marshal(cv)
+			if err != nil {
+				return err
+			}
+
+			_, err = tx.Pipelined(func(p redis.Pipeliner) error {
+				switch {
+				case cv.Status != ent.StatusArchived:
+					p.Set(storedCVKey(cvID), string(jsonCV), 0)
+				case archive:
+					p.Set(storedCVKey(cvID), string(jsonCV), 0)
+					p.ZRem(expiryKey, cvID)
+				default:
+					removeCV(p, cv)
+				}
+				return nil
+			})
+			if err == nil {
+				status = cv.Status
+			}
+			return err
+		})
+
+		switch {
+		case err == nil:
+			switch status {
+			case ent.StatusExpiring:
+				expiring++
+			case ent.StatusArchived:
+				expired++
+			}
+		case errors.Is(err, redis.TxFailedErr):
+		case errors.Is(err, ent.ErrCVNotFound):
+			rs.rd.ZRem(expiryKey, cvID)
+		default:
+			log.Println("redis error (sweep): ", err)
+			return expiring, expired, errors.New("bad response from database")
+		}
+	}
+
+	return expiring, expired, nil
+}
+
+func (rs *Redis) ListRevisions(c context.Context, id, cvID string) ([]ent.Revision, error) {
+	stored, err := rs.revisions(id, cvID)
+	if err != nil {
+		return nil, err
+	}
+
+	revisions := make([]ent.Revision, 0, len(stored))
+	for i := len(stored) - 1; i >= 0; i-- {
+		revisions = append(revisions, ent.Revision{Version: stored[i].Version, CreatedAt: stored[i].CreatedAt})
+	}
+	return revisions, nil
+}
+
+func (rs *Redis) GetRevision(c context.Context, id, cvID string, version int) (*ent.Revision, error) {
+	stored, err := rs.revisions(id, cvID)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range stored {
+		if stored[i].Version == version {
+			return &stored[i], nil
+		}
+	}
+	return nil, ent.ErrNoRevision
+}
+
+// revisions reads all revisions of CV owned by user, oldest first
+func (rs *Redis) revisions(id, cvID string) ([]ent.Revision, error) {
+	if _, err := rs.GetDataCV(context.Background(), id, cvID); err != nil {
+		if errors.Is(err, ent.ErrCVNotFound) {
+			return []ent.Revision{}, nil
+		}
+		return nil, err
+	}
+
+	values, err := rs.rd.LRange(revisionsKey(cvID), 0, -1).Result()
+	if err != nil {
+		log.Println("redis error (revisions): ", err)
+		return nil, errors.New("bad response from database")
+	}
+
+	photos, err := rs.rd.HGetAll(photosKey(cvID)).Result()
+	if err != nil {
+		log.Println("redis error (revision photos): ", err)
+		return nil, errors.New("bad response from database")
+	}
+
+	revisions := make([]ent.Revision, len(values))
+	for i, v := range values {
+		stored := storedRevision{CV: revisionData{CV: &ent.CV{}}}
+		if err := json.Unmarshal([]byte(v), &stored); err != nil {
+			log.Println(err)
+			return nil, errors.New("bad response from database")
+		}
+		if photo, ok := photos[stored.CV.PhotoHash]; ok {
+			stored.CV.Photo = []byte(photo)
+		}
+		revisions[i] = ent.Revision{Version: stored.Version, CreatedAt: stored.CreatedAt, CV: stored.CV.CV}
+	}
+	return revisions, nil
+}
+
+// DeleteCV moves CV to trash, see PurgeCV and PurgeTrash for removing it for good
+func (rs *Redis) DeleteCV(c context.Context, id, cvID string) error {
+	err := rs.watchCV(cvID, func(tx *redis.Tx, cv *ent.CV) error {
+		if cv.OwnerID != id || cv.DeletedAt != nil {
+			return ent.ErrCVNotFound
+		}
+
+		now := time.Now().UTC()
+		cv.DeletedAt = &now
+
+		jsonCV, err := json.Marshal(cv)
+		if err != nil {
+			return err
+		}
+
+		_, err = tx.Pipelined(func(p redis.Pipeliner) error {
+			p.Set(storedCVKey(cvID), string(jsonCV), 0)
+			p.ZRem(expiryKey, cvID)
+			p.ZAdd(trashKey, redis.Z{Score: float64(now.Unix()), Member: cvID})
+			return nil
+		})
+		return err
+	})
+	switch {
+	case err == nil:
+		return nil
+	case errors.Is(err, ent.ErrCVNotFound):
+		return err
+	default:
+		log.Println("redis error (delete cv): ", err)
+		return errors.New("bad response from database")
+	}
+}
+
+// ListTrash returns deleted CVs of user, the most recently deleted first
+func (rs *Redis) ListTrash(c context.Context, id string) ([]ent.CV, error) {
+	all, err := rs.allUserCVs(id)
+	if err != nil {
+		return nil, err
+	}
+
+	cvs := make([]ent.CV, 0, len(all))
+	for _, cv := range all {
+		if cv.DeletedAt != nil {
+			cvs = append(cvs, cv)
+		}
+	}
+
+	sort.Slice(cvs, func(i, j int) bool { return cvs[i].DeletedAt.After(*cvs[j].DeletedAt) })
+	return cvs, nil
+}
+
+// RestoreCV takes CV back from trash
+func (rs *Redis) RestoreCV(c context.Context, id, cvID string) error {
+	err := rs.watchCV(cvID, func(tx *redis.Tx, cv *ent.CV) error {
+		if cv.OwnerID != id || cv.DeletedAt == nil {
+			return ent.ErrCVNotFound
+		}
+
+		cv.DeletedAt = nil
+
+		jsonCV, err := json.Marshal(cv)
+		if err != nil {
+			return err
+		}
+
+		_, err = tx.Pipelined(func(p redis.Pipeliner) error {
+			p.Set(storedCVKey(cvID), string(jsonCV), 0)
+			p.ZRem(trashKey, cvID)
+			if cv.Status != ent.StatusArchived {
+				p.ZAdd(expiryKey, redis.Z{Score: float64(cv.ExpiresAt.Unix()), Member: cvID})
+			}
+			return nil
+		})
+		return err
+	})
+
+	switch {
+	case err == nil:
+		return nil
+	case errors.Is(err, ent.ErrCVNotFound):
+		return err
+	default:
+		log.Println("redis error (restore cv): ", err)
+		return errors.New("bad response from database")
+	}
+}
+
+// PurgeCV removes CV from trash for good
+func (rs *Redis) PurgeCV(c context.Context, id, cvID string) error {
+	err := rs.watchCV(cvID, func(tx *redis.Tx, cv *ent.CV) error {
+		if cv.OwnerID != id || cv.DeletedAt == nil {
+			return ent.ErrCVNotFound
+		}
+
+		_, err := tx.Pipelined(func(p redis.Pipeliner) error {
+			removeCV(p, cv)
+			return nil
+		})
+		return err
+	})
+
+	switch {
+	case err == nil:
+		return nil
+	case errors.Is(err, ent.ErrCVNotFound):
+		return err
+	default:
+		log.Println("redis error (purge cv): ", err)
+		return errors.New("bad response from database")
+	}
+}
+
+// PurgeTrash removes CVs deleted before the time
+func (rs *Redis) PurgeTrash(c context.Context, before time.Time) (int, error) {
+	ids, err := rs.rd.ZRangeByScore(trashKey, redis.ZRangeBy{
+		Min: "-inf",
+		Max: strconv.FormatInt(before.Unix(), 10),
+	}).Result()
+	if err != nil {
+		log.Println("redis error (purge trash): ", err)
+		return 0, errors.New("bad response from database")
+	}
+
+	purged := 0
+	for _, cvID := range ids {
+		err := rs.watchCV(cvID, func(tx *redis.Tx, cv *ent.CV) error {
+			if cv.DeletedAt == nil {
+				return nil
+			}
+
+			_, err := tx.Pipelined(func(p redis.Pipeliner) error {
+				removeCV(p, cv)
+				return nil
+			})
+			if err == nil {
+				purged++
+			}
+			return err
+		})
+
+		switch {
+		case err == nil, errors.Is(err, redis.TxFailedErr):
+		case errors.Is(err, ent.ErrCVNotFound):
+			rs.rd.ZRem(trashKey, cvID)
+		default:
+			log.Println("redis error (purge trash): ", err)
+			return purged, errors.New("bad response from database")
+		}
+	}
+
+	return purged, nil
+}
+
+// removeCV deletes CV with its revisions and their photos and drops it from all indexes
+func removeCV(p redis.Pipeliner, cv *ent.CV) {
+	p.Del(storedCVKey(cv.ID), revisionsKey(cv.ID), photosKey(cv.ID))
+	p.SRem(storedUserCVsKey(cv.OwnerID), cv.ID)
+	p.ZRem(expiryKey, cv.ID)
+	p.ZRem(trashKey, cv.ID)
+}
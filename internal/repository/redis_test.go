@@ -0,0 +1,142 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"log"
+	"os"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	ent "github.com/Vladroon22/CVmaker/internal/entity"
+	"github.com/go-redis/redis"
+)
+
+const (
+	benchUsers     = 10000
+	benchCVsOfUser = 10
+	// benchDB is flushed by the benchmark, so it must not be the DB of the app
+	benchDB = 15
+)
+
+// BenchmarkGetUserCVs lists CVs of one user among 100k CVs through the per-user
+// index and through the keyspace scan used before it. It needs redis given as
+// host:port by BenchRedis env:
+//
+//	BenchRedis=localhost:6379 go test ./internal/repository -run '^$' -bench GetUserCVs
+func BenchmarkGetUserCVs(b *testing.B) {
+	addr := os.Getenv("BenchRedis")
+	if addr == "" {
+		b.Skip("BenchRedis is not set")
+	}
+
+	log.SetOutput(io.Discard)
+	defer log.SetOutput(os.Stderr)
+
+	client := redis.NewClient(&redis.Options{Addr: addr, DB: benchDB})
+	if err := client.FlushDB().Err(); err != nil {
+		b.Fatal(err)
+	}
+	defer func() {
+		client.FlushDB()
+		client.Close()
+	}()
+
+	rs := &Redis{rd: client}
+	seedCVs(b, rs)
+	id := "user-0"
+
+	b.Run("index", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			cvs, err := rs.GetUserCVs(context.Background(), id)
+			if err != nil || len(cvs) != benchCVsOfUser {
+				b.Fatal(len(cvs), err)
+			}
+		}
+	})
+
+	b.Run("scan", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			cvs, err := scanUserCVs(rs, id)
+			if err != nil || len(cvs) != benchCVsOfUser {
+				b.Fatal(len(cvs), err)
+			}
+		}
+	})
+}
+
+// seedCVs stores benchCVsOfUser CVs for each of benchUsers users
+func seedCVs(b *testing.B, rs *Redis) {
+	b.Helper()
+
+	for u := 0; u < benchUsers; u++ {
+		id := "user-" + strconv.Itoa(u)
+		_, err := rs.rd.Pipelined(func(p redis.Pipeliner) error {
+			for n := 0; n < benchCVsOfUser; n++ {
+				cv := ent.CV{
+					ID:         id + "-cv-" + strconv.Itoa(n),
+					OwnerID:    id,
+					Name:       "Ivan",
+					Surname:    "Ivanov",
+					Profession: "Developer",
+					HardSkills: []string{"Go", "SQL"},
+					Version:    1,
+					CreatedAt:  time.Now().UTC(),
+				}
+				data, err := json.Marshal(cv)
+				if err != nil {
+					return err
+				}
+				p.Set(storedCVKey(cv.ID), data, 0)
+				p.SAdd(storedUserCVsKey(id), cv.ID)
+			}
+			return nil
+		})
+		if err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// scanUserCVs lists CVs the way it was done before the per-user index: scan of
+// all CV keys and a goroutine reading each CV to keep the ones owned by user
+func scanUserCVs(rs *Redis, id string) ([]ent.CV, error) {
+	var keys []string
+	var cursor uint64
+	for {
+		batch, next, err := rs.rd.Scan(cursor, storedCVKey("*"), 20).Result()
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, batch...)
+		if cursor = next; cursor == 0 {
+			break
+		}
+	}
+
+	var (
+		mtx sync.Mutex
+		wg  sync.WaitGroup
+		cvs []ent.CV
+	)
+	prefix := len(storedCVKey(""))
+	for _, key := range keys {
+		wg.Add(1)
+		go func(cvID string) {
+			defer wg.Done()
+			cv, err := rs.GetDataCV(context.Background(), id, cvID)
+			if err != nil {
+				return
+			}
+			mtx.Lock()
+			cvs = append(cvs, *cv)
+			mtx.Unlock()
+		}(key[prefix:])
+	}
+	wg.Wait()
+
+	return cvs, nil
+}
@@ -0,0 +1,74 @@
+package repository
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"strings"
+	"time"
+
+	ent "github.com/Vladroon22/CVmaker/internal/entity"
+)
+
+var (
+	errNoUser      = errors.New("no such user's email")
+	errWrongPass   = ent.ErrWrongPassword
+	errUserExisted = errors.New("such user's email allready existed")
+)
+
+// maxSessions is a number of sessions after which the history of user's sessions is cleared
+const maxSessions = 4
+
+// storedUser is a users row of storages without SQL
+type storedUser struct {
+	ID        string    `json:"id"`
+	Name      string    `json:"name"`
+	Email     string    `json:"email"`
+	Hash      string    `json:"hash_password"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type storedSession struct {
+	Device    string    `json:"device_type"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// emailDigest is what tombstone of deleted account keeps instead of the email itself
+func emailDigest(email string) string {
+	sum := sha256.Sum256([]byte(strings.ToLower(strings.TrimSpace(email))))
+	return hex.EncodeToString(sum[:])
+}
+
+// copyCV makes deep copy of CV so callers can't change stored data
+func copyCV(cv *ent.CV) (*ent.CV, error) {
+	data, err := json.Marshal(cv)
+	if err != nil {
+		return nil, err
+	}
+
+	cp := &ent.CV{}
+	if err := json.Unmarshal(data, cp); err != nil {
+		return nil, err
+	}
+	return cp, nil
+}
+
+// revisionData is CV as kept in revisions of postgres and redis, its photo is kept
+// once per CV and referred by SHA-256 so revisions don't copy it on every edit
+type revisionData struct {
+	*ent.CV
+	PhotoHash string `json:"photo_sha256,omitempty"`
+}
+
+// newRevisionData gives revision data of cv without the photo itself
+func newRevisionData(cv *ent.CV) revisionData {
+	snapshot := *cv
+	snapshot.Photo = nil
+	rev := revisionData{CV: &snapshot}
+	if len(cv.Photo) != 0 {
+		sum := sha256.Sum256(cv.Photo)
+		rev.PhotoHash = hex.EncodeToString(sum[:])
+	}
+	return rev
+}
@@ -2,26 +2,65 @@ package service
 
 import (
 	"context"
+	"strings"
+	"time"
 
 	ent "github.com/Vladroon22/CVmaker/internal/entity"
 )
 
-type Servicer interface {
-	SaveSession(context.Context, string, string) error
+type UserStorage interface {
 	Login(context.Context, string, string) (string, error)
 	CreateUser(context.Context, *ent.UserInput) error
-	GetProfessions(string) ([]string, error)
-	GetDataCV(string, string) (*ent.CV, error)
-	AddNewCV(*ent.CV) error
-	DeleteCV(string, string) error
+	GetUser(context.Context, string) (*ent.User, error)
+	DeleteUser(context.Context, string) error
+}
+
+type SessionStorage interface {
+	SaveSession(context.Context, string, string) error
+	ListSessions(context.Context, string) ([]ent.Session, error)
+}
+
+type CVStorage interface {
+	GetUserCVs(context.Context, string) ([]ent.CV, error)
+	GetAllUserCVs(context.Context, string) ([]ent.CV, error)
+	GetDataCV(context.Context, string, string) (*ent.CV, error)
+	AddNewCV(context.Context, *ent.CV) error
+	UpdateCV(context.Context, *ent.CV) error
+	DeleteCV(context.Context, string, string) error
+	ListRevisions(context.Context, string, string) ([]ent.Revision, error)
+	GetRevision(context.Context, string, string, int) (*ent.Revision, error)
+	ExtendCV(context.Context, string, string, time.Time) error
+	SetPDFTheme(context.Context, string, string, string) error
+	SweepCVs(context.Context, time.Time, time.Time, bool) (int, int, error)
+	ListTrash(context.Context, string) ([]ent.CV, error)
+	RestoreCV(context.Context, string, string) error
+	PurgeCV(context.Context, string, string) error
+	PurgeTrash(context.Context, time.Time) (int, error)
+}
+
+// Repository is a storage backend of the app, see repository package for implementations
+type Repository interface {
+	UserStorage
+	SessionStorage
+	CVStorage
+}
+
+type Servicer interface {
+	Repository
+	RestoreRevision(context.Context, string, string, int) (*ent.CV, error)
+	RenewCV(context.Context, string, string) (time.Time, error)
+	ListArchived(context.Context, string) ([]ent.CV, error)
+	DeleteAccount(context.Context, string, string) error
+	CloneCV(context.Context, string, string, string) (*ent.CV, error)
 }
 
 type Service struct {
-	repo Servicer
+	repo   Repository
+	policy ExpiryPolicy
 }
 
-func NewService(repo Servicer) Servicer {
-	return &Service{repo: repo}
+func NewService(repo Repository, policy ExpiryPolicy) Servicer {
+	return &Service{repo: repo, policy: policy}
 }
 
 func (s *Service) SaveSession(c context.Context, id string, device string) error {
@@ -36,18 +75,189 @@ func (s *Service) CreateUser(c context.Context, user *ent.UserInput) error {
 	return s.repo.CreateUser(c, user)
 }
 
-func (s *Service) GetProfessions(id string) ([]string, error) {
-	return s.repo.GetProfessions(id)
+func (s *Service) GetUser(c context.Context, id string) (*ent.User, error) {
+	return s.repo.GetUser(c, id)
+}
+
+func (s *Service) DeleteUser(c context.Context, id string) error {
+	return s.repo.DeleteUser(c, id)
+}
+
+// DeleteAccount removes user with sessions and CVs after the password is confirmed
+func (s *Service) DeleteAccount(c context.Context, id, pass string) error {
+	user, err := s.repo.GetUser(c, id)
+	if err != nil {
+		return err
+	}
+
+	loggedID, err := s.repo.Login(c, pass, user.Email)
+	if err != nil {
+		return err
+	}
+	if loggedID != id {
+		return ent.ErrWrongPassword
+	}
+
+	return s.repo.DeleteUser(c, id)
+}
+
+func (s *Service) ListSessions(c context.Context, id string) ([]ent.Session, error) {
+	return s.repo.ListSessions(c, id)
+}
+
+// GetAllUserCVs returns every CV of user, archived and deleted ones too
+func (s *Service) GetAllUserCVs(c context.Context, id string) ([]ent.CV, error) {
+	return s.repo.GetAllUserCVs(c, id)
+}
+
+func (s *Service) GetUserCVs(c context.Context, id string) ([]ent.CV, error) {
+	return s.repo.GetUserCVs(c, id)
+}
+
+func (s *Service) GetDataCV(c context.Context, id string, cvID string) (*ent.CV, error) {
+	return s.repo.GetDataCV(c, id, cvID)
+}
+
+func (s *Service) AddNewCV(c context.Context, cv *ent.CV) error {
+	cv.ExpiresAt = time.Now().UTC().Add(s.policy.Lifetime)
+	cv.Status = ent.StatusActive
+	return s.repo.AddNewCV(c, cv)
+}
+
+// CloneCV saves copy of CV as a new one under the profession, "(copy)" is added to the old one when it's empty
+func (s *Service) CloneCV(c context.Context, id, cvID, profession string) (*ent.CV, error) {
+	src, err := s.repo.GetDataCV(c, id, cvID)
+	if err != nil {
+		return nil, err
+	}
+
+	clone := *src
+	clone.ID = ""
+	clone.DeletedAt = nil
+	clone.SoftSkills = append([]string(nil), src.SoftSkills...)
+	clone.HardSkills = append([]string(nil), src.HardSkills...)
+	clone.Experience = append([]ent.Experience(nil), src.Experience...)
+	clone.Education = append(ent.EducationList(nil), src.Education...)
+	clone.Languages = append([]ent.Language(nil), src.Languages...)
+	clone.Links = append([]ent.Link(nil), src.Links...)
+	clone.Certificates = append([]ent.Certificate(nil), src.Certificates...)
+	clone.Photo = append([]byte(nil), src.Photo...)
+	clone.Projects = make([]ent.Project, 0, len(src.Projects))
+	for _, p := range src.Projects {
+		p.Stack = append([]string(nil), p.Stack...)
+		clone.Projects = append(clone.Projects, p)
+	}
+	clone.Profession = strings.TrimSpace(profession)
+	if clone.Profession == "" {
+		clone.Profession = src.Profession + " (copy)"
+	}
+
+	if err := s.AddNewCV(c, &clone); err != nil {
+		return nil, err
+	}
+	return &clone, nil
+}
+
+func (s *Service) UpdateCV(c context.Context, cv *ent.CV) error {
+	return s.repo.UpdateCV(c, cv)
+}
+
+// DeleteCV moves CV to trash, it stays there for policy.Trash before purge
+func (s *Service) DeleteCV(c context.Context, id, cvID string) error {
+	return s.repo.DeleteCV(c, id, cvID)
+}
+
+func (s *Service) ListTrash(c context.Context, id string) ([]ent.CV, error) {
+	cvs, err := s.repo.ListTrash(c, id)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range cvs {
+		if cvs[i].DeletedAt != nil {
+			cvs[i].PurgeAt = cvs[i].DeletedAt.Add(s.policy.Trash)
+		}
+	}
+	return cvs, nil
+}
+
+func (s *Service) RestoreCV(c context.Context, id, cvID string) error {
+	return s.repo.RestoreCV(c, id, cvID)
+}
+
+func (s *Service) PurgeCV(c context.Context, id, cvID string) error {
+	return s.repo.PurgeCV(c, id, cvID)
+}
+
+func (s *Service) PurgeTrash(c context.Context, before time.Time) (int, error) {
+	return s.repo.PurgeTrash(c, before)
 }
 
-func (s *Service) GetDataCV(id string, item string) (*ent.CV, error) {
-	return s.repo.GetDataCV(id, item)
+func (s *Service) ExtendCV(c context.Context, id, cvID string, until time.Time) error {
+	return s.repo.ExtendCV(c, id, cvID, until)
 }
 
-func (s *Service) AddNewCV(cv *ent.CV) error {
-	return s.repo.AddNewCV(cv)
+func (s *Service) SetPDFTheme(c context.Context, id, cvID, theme string) error {
+	return s.repo.SetPDFTheme(c, id, cvID, theme)
 }
 
-func (s *Service) DeleteCV(id, item string) error {
-	return s.repo.DeleteCV(id, item)
+// RenewCV prolongs life of CV for one more lifetime of policy from now
+func (s *Service) RenewCV(c context.Context, id, cvID string) (time.Time, error) {
+	until := time.Now().UTC().Add(s.policy.Lifetime)
+	if err := s.repo.ExtendCV(c, id, cvID, until); err != nil {
+		return time.Time{}, err
+	}
+	return until, nil
+}
+
+// ListArchived returns CVs of user archived on expiry, they are hidden from listing until renewed
+func (s *Service) ListArchived(c context.Context, id string) ([]ent.CV, error) {
+	all, err := s.repo.GetAllUserCVs(c, id)
+	if err != nil {
+		return nil, err
+	}
+
+	cvs := []ent.CV{}
+	for _, cv := range all {
+		if cv.Status == ent.StatusArchived && cv.DeletedAt == nil {
+			cvs = append(cvs, cv)
+		}
+	}
+	return cvs, nil
+}
+
+func (s *Service) SweepCVs(c context.Context, now, notice time.Time, archive bool) (int, int, error) {
+	return s.repo.SweepCVs(c, now, notice, archive)
+}
+
+func (s *Service) ListRevisions(c context.Context, id, cvID string) ([]ent.Revision, error) {
+	return s.repo.ListRevisions(c, id, cvID)
+}
+
+func (s *Service) GetRevision(c context.Context, id, cvID string, version int) (*ent.Revision, error) {
+	return s.repo.GetRevision(c, id, cvID, version)
+}
+
+// RestoreRevision saves content of the revision as the newest version of CV
+func (s *Service) RestoreRevision(c context.Context, id, cvID string, version int) (*ent.CV, error) {
+	current, err := s.repo.GetDataCV(c, id, cvID)
+	if err != nil {
+		return nil, err
+	}
+
+	rev, err := s.repo.GetRevision(c, id, cvID, version)
+	if err != nil {
+		return nil, err
+	}
+
+	restored := *rev.CV
+	restored.ID = cvID
+	restored.OwnerID = id
+	restored.Version = current.Version
+
+	if err := s.repo.UpdateCV(c, &restored); err != nil {
+		return nil, err
+	}
+
+	return &restored, nil
 }
@@ -0,0 +1,112 @@
+package service
+
+import (
+	"context"
+	"log"
+	"os"
+	"time"
+)
+
+const (
+	defaultCVLifetime    = time.Hour * 24 * 30
+	defaultCVNotice      = time.Hour * 24 * 3
+	defaultCVTrash       = time.Hour * 24 * 30
+	defaultSweepInterval = time.Minute * 10
+)
+
+// ExpiryPolicy describes how long CVs live, what happens to them after expiry
+// and how long deleted CVs stay in trash
+type ExpiryPolicy struct {
+	Lifetime time.Duration
+	Notice   time.Duration
+	Archive  bool
+	Trash    time.Duration
+}
+
+// ExpiryPolicyFromEnv reads CVLifetime, CVExpiryNotice, CVExpiryPolicy (archive|delete) and CVTrashPeriod
+func ExpiryPolicyFromEnv() ExpiryPolicy {
+	policy := ExpiryPolicy{
+		Lifetime: envDuration("CVLifetime", defaultCVLifetime),
+		Notice:   envDuration("CVExpiryNotice", defaultCVNotice),
+		Archive:  true,
+		Trash:    envDuration("CVTrashPeriod", defaultCVTrash),
+	}
+
+	switch mode := os.Getenv("CVExpiryPolicy"); mode {
+	case "", "archive":
+	case "delete":
+		policy.Archive = false
+	default:
+		log.Printf("unknown CVExpiryPolicy %q, archiving expired CVs", mode)
+	}
+
+	return policy
+}
+
+func envDuration(name string, def time.Duration) time.Duration {
+	value := os.Getenv(name)
+	if value == "" {
+		return def
+	}
+
+	d, err := time.ParseDuration(value)
+	if err != nil || d <= 0 {
+		log.Printf("wrong %s %q, using %s", name, value, def)
+		return def
+	}
+	return d
+}
+
+type Sweeper struct {
+	repo     Repository
+	policy   ExpiryPolicy
+	interval time.Duration
+}
+
+func NewSweeper(repo Repository, policy ExpiryPolicy) *Sweeper {
+	return &Sweeper{
+		repo:     repo,
+		policy:   policy,
+		interval: defaultSweepInterval,
+	}
+}
+
+// Run marks CVs expiring soon, archives or deletes expired ones and purges old trash until ctx is done
+func (s *Sweeper) Run(ctx context.Context) {
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		s.sweep(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (s *Sweeper) sweep(ctx context.Context) {
+	now := time.Now().UTC()
+
+	expiring, expired, err := s.repo.SweepCVs(ctx, now, now.Add(s.policy.Notice), s.policy.Archive)
+	if err != nil {
+		log.Println("sweep of CVs: ", err)
+		return
+	}
+
+	if expiring != 0 || expired != 0 {
+		log.Printf("Sweep of CVs: %d expiring soon, %d expired", expiring, expired)
+	}
+
+	purged, err := s.repo.PurgeTrash(ctx, now.Add(-s.policy.Trash))
+	if err != nil {
+		log.Println("purge of trash: ", err)
+		return
+	}
+
+	if purged != 0 {
+		log.Printf("Purge of trash: %d CVs removed", purged)
+	}
+}
@@ -0,0 +1,125 @@
+package utils
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"strconv"
+	"strings"
+
+	ent "github.com/Vladroon22/CVmaker/internal/entity"
+)
+
+type FieldChange struct {
+	Field string
+	Old   string
+	New   string
+}
+
+var cvFields = []struct {
+	name  string
+	value func(*ent.CV) string
+}{
+	{"Profession", func(cv *ent.CV) string { return cv.Profession }},
+	{"Name", func(cv *ent.CV) string { return cv.Name }},
+	{"Surname", func(cv *ent.CV) string { return cv.Surname }},
+	{"Birth date", func(cv *ent.CV) string {
+		if cv.BirthDate == nil {
+			return ""
+		}
+		return cv.BirthDate.Format("02.01.2006")
+	}},
+	{"Age hidden", func(cv *ent.CV) string { return strconv.FormatBool(cv.HideAge) }},
+	{"City", func(cv *ent.CV) string { return cv.LivingCity }},
+	{"Email", func(cv *ent.CV) string { return cv.EmailCV }},
+	{"Phone", func(cv *ent.CV) string { return cv.PhoneNumber }},
+	{"Salary", func(cv *ent.CV) string { return cv.SalaryText() }},
+	{"Education", func(cv *ent.CV) string {
+		entries := make([]string, 0, len(cv.Education))
+		for _, e := range cv.Education {
+			entry := strings.TrimSpace(e.Degree + " " + e.Field + ", " + e.Institution)
+			if years := e.Years(); years != "" {
+				entry += " (" + years + ")"
+			}
+			entries = append(entries, entry)
+		}
+		return strings.Join(entries, "; ")
+	}},
+	{"Experience", func(cv *ent.CV) string {
+		jobs := make([]string, 0, len(cv.Experience))
+		for _, e := range cv.Experience {
+			job := e.Position + " at " + e.Company + ", " + e.City + " (" + e.Period() + ")"
+			if len(e.Bullets) != 0 {
+				job += ": " + strings.Join(e.Bullets, " / ")
+			}
+			jobs = append(jobs, job)
+		}
+		return strings.Join(jobs, "; ")
+	}},
+	{"Languages", func(cv *ent.CV) string {
+		languages := make([]string, 0, len(cv.Languages))
+		for _, l := range cv.Languages {
+			languages = append(languages, l.Name+" ("+l.Level+")")
+		}
+		return strings.Join(languages, ", ")
+	}},
+	{"Links", func(cv *ent.CV) string {
+		links := make([]string, 0, len(cv.Links))
+		for _, l := range cv.Links {
+			links = append(links, l.Kind+": "+l.Text()+" ("+l.URL+")")
+		}
+		return strings.Join(links, "; ")
+	}},
+	{"Projects", func(cv *ent.CV) string {
+		projects := make([]string, 0, len(cv.Projects))
+		for _, p := range cv.Projects {
+			project := p.Name
+			if p.URL != "" {
+				project += " (" + p.URL + ")"
+			}
+			if len(p.Stack) != 0 {
+				project += " [" + strings.Join(p.Stack, ", ") + "]"
+			}
+			if p.Description != "" {
+				project += ": " + p.Description
+			}
+			projects = append(projects, project)
+		}
+		return strings.Join(projects, "; ")
+	}},
+	{"Certificates", func(cv *ent.CV) string {
+		certificates := make([]string, 0, len(cv.Certificates))
+		for _, c := range cv.Certificates {
+			certificate := c.Name + " by " + c.Issuer + " (" + c.Dates() + ")"
+			if c.CredentialID != "" {
+				certificate += " #" + c.CredentialID
+			}
+			if c.URL != "" {
+				certificate += " " + c.URL
+			}
+			certificates = append(certificates, certificate)
+		}
+		return strings.Join(certificates, "; ")
+	}},
+	{"Photo", func(cv *ent.CV) string {
+		if len(cv.Photo) == 0 {
+			return ""
+		}
+		sum := sha256.Sum256(cv.Photo)
+		return "image " + hex.EncodeToString(sum[:4])
+	}},
+	{"Hard Skills", func(cv *ent.CV) string { return strings.Join(cv.HardSkills, ", ") }},
+	{"Soft Skills", func(cv *ent.CV) string { return strings.Join(cv.SoftSkills, ", ") }},
+	{"About", func(cv *ent.CV) string { return cv.Description }},
+}
+
+// DiffCV returns fields whose values differ between two versions of CV
+func DiffCV(from, to *ent.CV) []FieldChange {
+	changes := []FieldChange{}
+	for _, f := range cvFields {
+		oldVal, newVal := f.value(from), f.value(to)
+		if oldVal != newVal {
+			changes = append(changes, FieldChange{Field: f.name, Old: oldVal, New: newVal})
+		}
+	}
+	return changes
+}
@@ -0,0 +1,96 @@
+package utils
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/jpeg"
+	_ "image/png"
+	"net/http"
+)
+
+const (
+	MaxPhotoSize   = 5 << 20 // 5 MB
+	PhotoThumbSize = 256
+	// maxPhotoPixels limits decoded images so small files can't blow up in memory
+	maxPhotoPixels = 40_000_000
+)
+
+var (
+	ErrPhotoType  = errors.New("photo must be a JPEG or PNG image")
+	ErrPhotoLarge = errors.New("photo is too large (max 5 MB)")
+)
+
+// MakeThumbnail sniffs type of uploaded photo, crops the centre square of it
+// and scales it to PhotoThumbSize, the result is JPEG
+func MakeThumbnail(data []byte) ([]byte, error) {
+	if len(data) > MaxPhotoSize {
+		return nil, ErrPhotoLarge
+	}
+
+	switch http.DetectContentType(data) {
+	case "image/jpeg", "image/png":
+	default:
+		return nil, ErrPhotoType
+	}
+
+	cfg, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, ErrPhotoType
+	}
+	if cfg.Width == 0 || cfg.Height == 0 || cfg.Width*cfg.Height > maxPhotoPixels {
+		return nil, ErrPhotoLarge
+	}
+
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, ErrPhotoType
+	}
+
+	thumb := squareThumbnail(img, PhotoThumbSize)
+
+	buf := &bytes.Buffer{}
+	if err := jpeg.Encode(buf, thumb, &jpeg.Options{Quality: 85}); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// squareThumbnail crops the centre square of img and scales it to size x size
+// averaging source pixels under every target pixel, transparency becomes white
+func squareThumbnail(img image.Image, size int) *image.RGBA {
+	b := img.Bounds()
+	side := min(b.Dx(), b.Dy())
+	crop := image.Rect(0, 0, side, side).Add(image.Pt(b.Min.X+(b.Dx()-side)/2, b.Min.Y+(b.Dy()-side)/2))
+
+	src := image.NewRGBA(image.Rect(0, 0, side, side))
+	draw.Draw(src, src.Bounds(), image.White, image.Point{}, draw.Src)
+	draw.Draw(src, src.Bounds(), img, crop.Min, draw.Over)
+
+	if side <= size {
+		size = side
+	}
+
+	dst := image.NewRGBA(image.Rect(0, 0, size, size))
+	for y := 0; y < size; y++ {
+		y0, y1 := y*side/size, max((y+1)*side/size, y*side/size+1)
+		for x := 0; x < size; x++ {
+			x0, x1 := x*side/size, max((x+1)*side/size, x*side/size+1)
+
+			var r, g, bl, n uint32
+			for sy := y0; sy < y1; sy++ {
+				for sx := x0; sx < x1; sx++ {
+					c := src.RGBAAt(sx, sy)
+					r += uint32(c.R)
+					g += uint32(c.G)
+					bl += uint32(c.B)
+					n++
+				}
+			}
+			dst.SetRGBA(x, y, color.RGBA{uint8(r / n), uint8(g / n), uint8(bl / n), 255})
+		}
+	}
+	return dst
+}
@@ -2,6 +2,7 @@ package utils
 
 import (
 	"errors"
+	"net/url"
 	"os"
 	"regexp"
 	"strings"
@@ -13,8 +14,8 @@ import (
 )
 
 const (
-	TTLofJWT = time.Minute * 40
-	TTLofCV  = time.Hour * 24 * 7
+	TTLofJWT     = time.Minute * 40
+	TTLofCVCache = time.Hour * 24
 )
 
 var SignKey = []byte(os.Getenv("KEY"))
@@ -35,6 +36,19 @@ func ValidateEmail(email string) bool {
 	return emailRegex.MatchString(email)
 }
 
+// ValidateURL accepts only absolute http(s) links
+func ValidateURL(link string) bool {
+	if len(link) > 2048 {
+		return false
+	}
+
+	u, err := url.Parse(link)
+	if err != nil {
+		return false
+	}
+	return (u.Scheme == "http" || u.Scheme == "https") && u.Host != ""
+}
+
 func ValidatePhone(phone string) bool {
 	phoneRegex := regexp.MustCompile(`^(?:\+7|8)?[\s-]?\(?\d{3}\)?[\s-]?\d{2,3}[\s-]?\d{2,3}[\s-]?\d{2,4}$`)
 	return phoneRegex.MatchString(phone)
@@ -70,15 +84,41 @@ func ValidateDataAge(data string) bool {
 	}
 }
 
-func CountUserAge(userAge time.Time) int {
-	currTime := time.Now()
-	currAge := currTime.Year() - userAge.Year()
+// ParseMonth reads month as YYYY-MM from <input type="month"> or as MM.YYYY typed by hand
+func ParseMonth(month string) (time.Time, error) {
+	if t, err := time.Parse("2006-01", month); err == nil {
+		return t, nil
+	}
+	return time.Parse("01.2006", month)
+}
 
-	if currTime.Month() < userAge.Month() || currTime.Month() == userAge.Month() && currTime.Day() < userAge.Day() {
-		currAge--
+// ParseDate reads date as YYYY-MM-DD from <input type="date"> or as DD.MM.YYYY typed by hand
+func ParseDate(date string) (time.Time, error) {
+	if t, err := time.Parse("2006-01-02", date); err == nil {
+		return t, nil
 	}
+	return time.Parse("02.01.2006", date)
+}
 
-	return currAge
+// ParseSkills splits values by commas, semicolons and new lines into skills with
+// whitespace collapsed, empty and repeated (ignoring case) skills are dropped
+func ParseSkills(values ...string) []string {
+	skills := []string{}
+	seen := map[string]bool{}
+	for _, value := range values {
+		for _, skill := range strings.FieldsFunc(value, func(r rune) bool {
+			return r == ',' || r == ';' || r == '\n' || r == '\r'
+		}) {
+			skill = strings.Join(strings.Fields(skill), " ")
+			key := strings.ToLower(skill)
+			if skill == "" || seen[key] {
+				continue
+			}
+			seen[key] = true
+			skills = append(skills, skill)
+		}
+	}
+	return skills
 }
 
 func Valid(user *ent.UserInput) error {
@@ -0,0 +1,33 @@
+package utils
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseSkills(t *testing.T) {
+	tests := []struct {
+		name   string
+		values []string
+		want   []string
+	}{
+		{"empty", nil, []string{}},
+		{"blank", []string{"", "  ", " , ;\n"}, []string{}},
+		{"commas", []string{"Go, SQL,Docker"}, []string{"Go", "SQL", "Docker"}},
+		{"semicolons", []string{"Go; SQL ;Docker"}, []string{"Go", "SQL", "Docker"}},
+		{"new lines", []string{"Go\nSQL\r\nDocker"}, []string{"Go", "SQL", "Docker"}},
+		{"spaces are kept inside skill", []string{"Docker   Compose,  CI/CD  "}, []string{"Docker Compose", "CI/CD"}},
+		{"empty skills dropped", []string{"Go,,;, SQL,"}, []string{"Go", "SQL"}},
+		{"duplicates ignoring case", []string{"Go, go, GO, SQL, sql"}, []string{"Go", "SQL"}},
+		{"duplicates across values", []string{"Go, SQL", "sql; Redis"}, []string{"Go", "SQL", "Redis"}},
+		{"order kept", []string{"Redis, Go, Kafka"}, []string{"Redis", "Go", "Kafka"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ParseSkills(tt.values...); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseSkills(%q) = %q, want %q", tt.values, got, tt.want)
+			}
+		})
+	}
+}